@@ -0,0 +1,399 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- Интеграция с ИИ-агентом ---
+
+// AIProvider - LLM-провайдер, превращающий запрос пользователя в фильтр задач
+type AIProvider interface {
+	Name() string
+	InferFilter(ctx context.Context, query string) (TaskFilter, error)
+}
+
+var aiProvider AIProvider // Провайдер, выбранный через AI_PROVIDER (nil - только ключевые слова)
+
+// Инструкция для LLM: отвечать только JSON-объектом в формате TaskFilter
+const aiSystemPrompt = `You convert a user's request about their task list into a JSON filter.
+Respond with a single JSON object and nothing else. Allowed keys:
+"preset" (one of "active", "archived", "completed", "overdue", "inbox"), "search" (string), "priority" (one of "high", "medium", "low"),
+"isCompleted" (boolean), "status" (one of "todo", "in_progress", "blocked", "done"), "tag" (string),
+"assignee" ("me" for the current user, "none" for unassigned tasks), "dueBefore" and "dueAfter" (RFC3339 timestamps).
+Omit keys that the request does not mention. Current time: %s.`
+
+// aiHTTPClient - HTTP-клиент для обращений к LLM API
+var aiHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// aiSlots - Семафор одновременных обращений к LLM (AI_MAX_CONCURRENCY, 0 - без ограничения)
+// Буферизованный канал: запись занимает слот, чтение освобождает.
+var aiSlots chan struct{}
+
+// aiRetryAfter - Подсказка клиенту в Retry-After, когда все слоты заняты (в секундах)
+const aiRetryAfter = "2"
+
+// acquireAISlot - Занять слот без ожидания; false - все слоты заняты
+func acquireAISlot() bool {
+	if aiSlots == nil {
+		return true
+	}
+	select {
+	case aiSlots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// releaseAISlot - Освободить слот, занятый acquireAISlot
+func releaseAISlot() {
+	if aiSlots != nil {
+		<-aiSlots
+	}
+}
+
+// aiEmptyResult - Что отвечать, если запрос не удалось разобрать (AI_EMPTY_RESULT):
+// "all" - вернуть все задачи, "clarify" - пустой список и уточняющий вопрос
+var aiEmptyResult = "all"
+
+// aiClarificationQuestion - Вопрос, который предлагается задать пользователю при режиме "clarify"
+const aiClarificationQuestion = "Which tasks do you mean? Try mentioning a priority, status, tag or due date, e.g. \"high priority tasks due this week\"."
+
+// initAI - Выбрать LLM-провайдера по переменной окружения AI_PROVIDER
+func initAI() {
+	switch aiEmptyResult = strings.ToLower(getEnv("AI_EMPTY_RESULT", "all")); aiEmptyResult {
+	case "all", "clarify":
+	default:
+		log.Fatalf("Unknown AI_EMPTY_RESULT %q (expected all or clarify)", aiEmptyResult)
+	}
+	if limit := getEnvInt("AI_MAX_CONCURRENCY", 10); limit > 0 {
+		aiSlots = make(chan struct{}, limit)
+	}
+	if aiMaxQueryLength = getEnvInt("AI_MAX_QUERY_LENGTH", aiMaxQueryLength); aiMaxQueryLength < 1 {
+		log.Fatalf("AI_MAX_QUERY_LENGTH must be positive, got %d", aiMaxQueryLength)
+	}
+	aiTimeout = getEnvDuration("AI_TIMEOUT", aiTimeout)
+	aiBreaker.threshold = getEnvInt("AI_BREAKER_THRESHOLD", aiBreaker.threshold)
+	aiBreaker.cooldown = getEnvDuration("AI_BREAKER_COOLDOWN", aiBreaker.cooldown)
+	aiCache.ttl = getEnvDuration("AI_CACHE_TTL", aiCache.ttl)
+	aiCache.size = getEnvInt("AI_CACHE_SIZE", aiCache.size)
+	if !featureEnabled("ai") {
+		log.Println("AI feature is disabled (FEATURE_AI=false).")
+		return
+	}
+	switch name := strings.ToLower(os.Getenv("AI_PROVIDER")); name {
+	case "":
+		log.Println("AI_PROVIDER is not set, AI queries will use keyword matching only.")
+	case "openai":
+		aiProvider = &OpenAIProvider{
+			APIKey: os.Getenv("OPENAI_API_KEY"),
+			Model:  getEnv("OPENAI_MODEL", "gpt-4o-mini"),
+		}
+	case "gemini":
+		aiProvider = &GeminiProvider{
+			APIKey: os.Getenv("GOOGLE_API_KEY"),
+			Model:  getEnv("GEMINI_MODEL", "gemini-1.5-flash"),
+		}
+	case "mock":
+		aiProvider = &MockProvider{}
+	default:
+		log.Fatalf("Unknown AI_PROVIDER %q (expected openai, gemini or mock)", name)
+	}
+	if aiProvider != nil {
+		log.Printf("AI provider: %s", aiProvider.Name())
+	}
+}
+
+// parseFilterJSON - Разобрать JSON-ответ модели в TaskFilter
+func parseFilterJSON(content string) (TaskFilter, error) {
+	var filter TaskFilter
+	content = strings.TrimSpace(content)
+	// Модели иногда оборачивают JSON в markdown-блок
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+	if err := json.Unmarshal([]byte(strings.TrimSpace(content)), &filter); err != nil {
+		return filter, fmt.Errorf("invalid filter JSON from model: %w", err)
+	}
+	return filter, nil
+}
+
+// postJSON - Отправить JSON-запрос к LLM API и декодировать ответ
+func postJSON(ctx context.Context, url string, headers map[string]string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := aiHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("provider returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// OpenAIProvider - Провайдер на основе OpenAI Chat Completions API
+type OpenAIProvider struct {
+	APIKey string
+	Model  string
+}
+
+func (p *OpenAIProvider) Name() string { return "openai" }
+
+func (p *OpenAIProvider) InferFilter(ctx context.Context, query string) (TaskFilter, error) {
+	if p.APIKey == "" {
+		return TaskFilter{}, errors.New("OPENAI_API_KEY is not set")
+	}
+
+	body := gin.H{
+		"model": p.Model,
+		"messages": []gin.H{
+			{"role": "system", "content": fmt.Sprintf(aiSystemPrompt, time.Now().Format(time.RFC3339))},
+			{"role": "user", "content": query},
+		},
+		"response_format": gin.H{"type": "json_object"},
+	}
+	var resp struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	headers := map[string]string{"Authorization": "Bearer " + p.APIKey}
+	if err := postJSON(ctx, "https://api.openai.com/v1/chat/completions", headers, body, &resp); err != nil {
+		return TaskFilter{}, err
+	}
+	if len(resp.Choices) == 0 {
+		return TaskFilter{}, errors.New("openai returned no choices")
+	}
+	return parseFilterJSON(resp.Choices[0].Message.Content)
+}
+
+// GeminiProvider - Провайдер на основе Google Gemini API
+type GeminiProvider struct {
+	APIKey string
+	Model  string
+}
+
+func (p *GeminiProvider) Name() string { return "gemini" }
+
+func (p *GeminiProvider) InferFilter(ctx context.Context, query string) (TaskFilter, error) {
+	if p.APIKey == "" {
+		return TaskFilter{}, errors.New("GOOGLE_API_KEY is not set")
+	}
+
+	body := gin.H{
+		"systemInstruction": gin.H{
+			"parts": []gin.H{{"text": fmt.Sprintf(aiSystemPrompt, time.Now().Format(time.RFC3339))}},
+		},
+		"contents": []gin.H{
+			{"role": "user", "parts": []gin.H{{"text": query}}},
+		},
+		"generationConfig": gin.H{"responseMimeType": "application/json"},
+	}
+	var resp struct {
+		Candidates []struct {
+			Content struct {
+				Parts []struct {
+					Text string `json:"text"`
+				} `json:"parts"`
+			} `json:"content"`
+		} `json:"candidates"`
+	}
+	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent", p.Model)
+	headers := map[string]string{"x-goog-api-key": p.APIKey}
+	if err := postJSON(ctx, url, headers, body, &resp); err != nil {
+		return TaskFilter{}, err
+	}
+	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
+		return TaskFilter{}, errors.New("gemini returned no candidates")
+	}
+	return parseFilterJSON(resp.Candidates[0].Content.Parts[0].Text)
+}
+
+// MockProvider - Провайдер для тестов: возвращает заранее заданный фильтр или ошибку
+type MockProvider struct {
+	Filter TaskFilter
+	Err    error
+}
+
+func (p *MockProvider) Name() string { return "mock" }
+
+func (p *MockProvider) InferFilter(ctx context.Context, query string) (TaskFilter, error) {
+	return p.Filter, p.Err
+}
+
+// keywordFilter - Простое сопоставление по ключевым словам (запасной вариант без LLM)
+func keywordFilter(query string) (TaskFilter, bool) {
+	completed, notCompleted := true, false
+	switch query {
+	case "покажи срочные":
+		return TaskFilter{Priority: PriorityHigh}, true
+	case "покажи завершенные":
+		return TaskFilter{IsCompleted: &completed}, true
+	case "покажи незавершенные":
+		return TaskFilter{IsCompleted: &notCompleted}, true
+	}
+	return TaskFilter{}, false
+}
+
+// aiMaxQueryLength - Максимальная длина запроса к ИИ-агенту в символах (AI_MAX_QUERY_LENGTH)
+// Весь запрос уходит в LLM, поэтому длина ограничивает и стоимость, и объем подсовываемых инструкций.
+var aiMaxQueryLength = 500
+
+// sanitizeAIQuery - Убрать управляющие символы: переводы строк и табуляции заменяются пробелом,
+// остальные (включая ESC и NUL) удаляются
+func sanitizeAIQuery(query string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		switch {
+		case r == '\n' || r == '\r' || r == '\t':
+			return ' '
+		case unicode.IsControl(r):
+			return -1
+		}
+		return r
+	}, query))
+}
+
+// cleanInferredFilter - Отбросить значения фильтра от модели, которые не проходят проверку
+// Модель может вернуть приоритет по-русски или выдуманное значение.
+func cleanInferredFilter(filter TaskFilter) TaskFilter {
+	var err error
+	if filter.Priority, err = normalizePriority(filter.Priority); err != nil {
+		log.Printf("AI provider %s returned %v, ignoring priority", aiProvider.Name(), err)
+	}
+	if err := validStatus(filter.Status); filter.Status != "" && err != nil {
+		log.Printf("AI provider %s returned %v, ignoring status", aiProvider.Name(), err)
+		filter.Status = ""
+	}
+	if err := validTagFilter(filter.Tag); err != nil {
+		log.Printf("AI provider %s returned %v, ignoring tag", aiProvider.Name(), err)
+		filter.Tag = ""
+	}
+	if _, ok := taskPresets[filter.Preset]; filter.Preset != "" && !ok {
+		log.Printf("AI provider %s returned unknown preset %q, ignoring it", aiProvider.Name(), filter.Preset)
+		filter.Preset = ""
+	}
+	return filter
+}
+
+// AIProcessQuery - Конечная точка для обработки запросов к ИИ-агенту
+// Фильтр, выведенный провайдером, кэшируется на AI_CACHE_TTL (заголовок X-Cache: HIT или MISS).
+func AIProcessQuery(c *gin.Context) {
+	var requestBody struct {
+		Query string `json:"query" binding:"required"`
+	}
+	if err := bindJSON(c, &requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query is required"})
+		return
+	}
+
+	userQuery := sanitizeAIQuery(requestBody.Query)
+	if userQuery == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query is required"})
+		return
+	}
+	if length := utf8.RuneCountInString(userQuery); length > aiMaxQueryLength {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("query is too long: %d characters, maximum is %d", length, aiMaxQueryLength),
+			"code":  "query_too_long",
+		})
+		return
+	}
+	log.Printf("Received AI query: \"%s\"", userQuery)
+
+	// 1. Запрос к LLM-провайдеру, 2. при ошибке - сопоставление по ключевым словам
+	var filter TaskFilter
+	source := "keywords"
+	fallbackReason := "" // Почему провайдер не использован (пусто, если он не настроен или ответил)
+	if aiProvider != nil {
+		key := aiCacheKey(aiProvider.Name(), userQuery)
+		if cached, ok := aiCache.get(key, time.Now()); ok {
+			c.Header("X-Cache", "HIT")
+			filter = cached
+			source = aiProvider.Name()
+		} else {
+			c.Header("X-Cache", "MISS")
+			if !acquireAISlot() {
+				c.Header("Retry-After", aiRetryAfter)
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Too many AI queries in progress, try again later", "code": "ai_busy"})
+				return
+			}
+			inferred, reason := inferWithFallback(c.Request.Context(), userQuery)
+			releaseAISlot()
+			if reason != "" {
+				fallbackReason = reason
+			} else {
+				filter = cleanInferredFilter(inferred)
+				source = aiProvider.Name()
+				// Кэшируется только ответ провайдера: запасной вариант и так бесплатный
+				aiCache.put(key, filter, time.Now())
+			}
+		}
+	}
+	if source == "keywords" {
+		var matched bool
+		if filter, matched = keywordFilter(userQuery); !matched && aiEmptyResult == "clarify" {
+			log.Println("AI could not provide specific filters, asking for clarification.")
+			c.JSON(http.StatusOK, gin.H{
+				"message":             fmt.Sprintf("Could not understand AI query: '%s'", userQuery),
+				"source":              source,
+				"usedFallback":        fallbackReason != "",
+				"fallbackReason":      fallbackReason,
+				"clarificationNeeded": true,
+				"suggestedQuestion":   aiClarificationQuestion,
+				"filteredTasks":       []Task{},
+			})
+			return
+		} else if !matched {
+			log.Println("AI could not provide specific filters, returning all tasks.")
+		}
+	}
+
+	// 3. Выборка задач тем же путем, что и GET /tasks (?page=, ?pageSize=, ?sort=)
+	filteredTasks, meta, ok := findTaskPage(c, filter)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":             fmt.Sprintf("Processing AI query: '%s'", userQuery),
+		"filter":              filter,
+		"source":              source,
+		"usedFallback":        fallbackReason != "",
+		"fallbackReason":      fallbackReason,
+		"clarificationNeeded": false,
+		"filteredTasks":       filteredTasks,
+		"meta":                meta,
+		"links":               listLinks(c, meta),
+	})
+}
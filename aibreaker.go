@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- Защита от сбоев LLM-провайдера ---
+
+// Таймаут одного обращения к провайдеру (AI_TIMEOUT). Отдельно от aiHTTPClient:
+// покрывает и ожидание соединения, и чтение ответа, и отмену клиентом запроса.
+var aiTimeout = 10 * time.Second
+
+// Причины перехода на сопоставление по ключевым словам (поле fallbackReason в ответе)
+const (
+	fallbackTimeout     = "timeout"
+	fallbackError       = "provider_error"
+	fallbackCircuitOpen = "circuit_open"
+)
+
+// aiMetrics - Счетчики обращений к провайдеру с момента запуска (GET /ai/metrics)
+var aiMetrics struct {
+	Calls        atomic.Int64
+	Failures     atomic.Int64
+	Timeouts     atomic.Int64
+	ShortCircuit atomic.Int64
+}
+
+// circuitBreaker - После threshold сбоев подряд провайдер не вызывается в течение cooldown
+// По истечении паузы пропускается один пробный запрос: успех закрывает цепь, сбой открывает снова.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// aiBreaker - Автомат для текущего провайдера (AI_BREAKER_THRESHOLD, AI_BREAKER_COOLDOWN; порог 0 - отключен)
+var aiBreaker = &circuitBreaker{threshold: 5, cooldown: time.Minute}
+
+// allow - Можно ли сейчас обращаться к провайдеру
+func (b *circuitBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.threshold <= 0 || b.failures < b.threshold {
+		return true
+	}
+	if now.Before(b.openUntil) || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record - Учесть результат обращения
+func (b *circuitBreaker) record(now time.Time, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if err == nil {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.threshold > 0 && b.failures >= b.threshold {
+		b.openUntil = now.Add(b.cooldown)
+		log.Printf("AI circuit breaker open for %s after %d consecutive failures", b.cooldown, b.failures)
+	}
+}
+
+// state - Состояние для GET /ai/metrics: closed, open или half_open (ждет пробного запроса)
+func (b *circuitBreaker) state(now time.Time) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case b.threshold <= 0 || b.failures < b.threshold:
+		return "closed"
+	case now.Before(b.openUntil):
+		return "open"
+	default:
+		return "half_open"
+	}
+}
+
+// inferWithFallback - Обратиться к провайдеру с таймаутом и автоматом
+// Пустая причина - фильтр получен от провайдера; иначе вызывающий переходит на ключевые слова.
+func inferWithFallback(ctx context.Context, query string) (TaskFilter, string) {
+	if !aiBreaker.allow(time.Now()) {
+		aiMetrics.ShortCircuit.Add(1)
+		return TaskFilter{}, fallbackCircuitOpen
+	}
+	aiMetrics.Calls.Add(1)
+	ctx, cancel := context.WithTimeout(ctx, aiTimeout)
+	defer cancel()
+	filter, err := aiProvider.InferFilter(ctx, query)
+	aiBreaker.record(time.Now(), err)
+	if err == nil {
+		return filter, ""
+	}
+	aiMetrics.Failures.Add(1)
+	reason := fallbackError
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		aiMetrics.Timeouts.Add(1)
+		reason = fallbackTimeout
+	}
+	log.Printf("AI provider %s failed (%s), falling back to keyword matching: %v", aiProvider.Name(), reason, err)
+	return TaskFilter{}, reason
+}
+
+// GetAIMetrics - Счетчики обращений к провайдеру и состояние автомата (GET /ai/metrics)
+func GetAIMetrics(c *gin.Context) {
+	provider := ""
+	if aiProvider != nil {
+		provider = aiProvider.Name()
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"provider":       provider,
+		"calls":          aiMetrics.Calls.Load(),
+		"failures":       aiMetrics.Failures.Load(),
+		"timeouts":       aiMetrics.Timeouts.Load(),
+		"shortCircuited": aiMetrics.ShortCircuit.Load(),
+		"breaker":        aiBreaker.state(time.Now()),
+	})
+}
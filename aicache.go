@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --- Кэш фильтров ИИ-агента ---
+
+// aiFilterCache - Фильтры, выведенные провайдером для недавних запросов (ключ - нормализованный запрос)
+// Инвалидации нет: фильтр зависит только от текста запроса, а относительные сроки в нем
+// ("на этой неделе") устаревают не быстрее AI_CACHE_TTL.
+type aiFilterCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration // AI_CACHE_TTL, 0 - кэш выключен
+	size    int           // AI_CACHE_SIZE - максимум записей
+	entries map[string]aiCacheEntry
+}
+
+// aiCacheEntry - Фильтр хранится в JSON: resolveAssignee меняет OrGroups на месте,
+// и общий срез из кэша подставил бы одному пользователю "me" другого
+type aiCacheEntry struct {
+	filter  []byte
+	expires time.Time
+}
+
+var aiCache = &aiFilterCache{ttl: time.Minute, size: 1000, entries: make(map[string]aiCacheEntry)}
+
+// aiCacheKey - Ключ кэша: провайдер и запрос без учета регистра и лишних пробелов
+func aiCacheKey(provider, query string) string {
+	return provider + "\x00" + strings.ToLower(strings.Join(strings.Fields(query), " "))
+}
+
+// get - Копия закэшированного фильтра, если он еще не устарел
+func (ac *aiFilterCache) get(key string, now time.Time) (TaskFilter, bool) {
+	ac.mu.Lock()
+	entry, ok := ac.entries[key]
+	if ok && !now.Before(entry.expires) {
+		delete(ac.entries, key)
+		ok = false
+	}
+	ac.mu.Unlock()
+
+	var filter TaskFilter
+	if !ok || json.Unmarshal(entry.filter, &filter) != nil {
+		return TaskFilter{}, false
+	}
+	return filter, true
+}
+
+// put - Запомнить фильтр; при переполнении сначала выбрасываются устаревшие записи,
+// а если места все равно нет - новый фильтр не кэшируется
+func (ac *aiFilterCache) put(key string, filter TaskFilter, now time.Time) {
+	if ac.ttl <= 0 {
+		return
+	}
+	data, err := json.Marshal(filter)
+	if err != nil {
+		return
+	}
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	if _, exists := ac.entries[key]; !exists && len(ac.entries) >= ac.size {
+		for k, entry := range ac.entries {
+			if !now.Before(entry.expires) {
+				delete(ac.entries, k)
+			}
+		}
+		if len(ac.entries) >= ac.size {
+			return
+		}
+	}
+	ac.entries[key] = aiCacheEntry{filter: data, expires: now.Add(ac.ttl)}
+}
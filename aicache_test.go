@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAICacheKey(t *testing.T) {
+	if aiCacheKey("openai", "  Срочные   задачи\tна неделе ") != aiCacheKey("openai", "срочные задачи на неделе") {
+		t.Error("queries differing only in case and whitespace have different keys")
+	}
+	if aiCacheKey("openai", "срочные") == aiCacheKey("ollama", "срочные") {
+		t.Error("the same query for different providers has the same key")
+	}
+}
+
+// TestAIFilterCacheTTL - Запись живет AI_CACHE_TTL, а фильтр из кэша - независимая копия
+func TestAIFilterCacheTTL(t *testing.T) {
+	cache := &aiFilterCache{ttl: time.Minute, size: 10, entries: make(map[string]aiCacheEntry)}
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	key := aiCacheKey("openai", "мои задачи")
+	cache.put(key, TaskFilter{Priority: PriorityHigh, OrGroups: [][]TaskFilter{{{Assignee: "me"}}}}, now)
+
+	got, ok := cache.get(key, now.Add(59*time.Second))
+	if !ok || got.Priority != PriorityHigh || got.OrGroups[0][0].Assignee != "me" {
+		t.Fatalf("get before TTL = %+v, %v", got, ok)
+	}
+	got.OrGroups[0][0].Assignee = "alice"
+	if again, _ := cache.get(key, now); again.OrGroups[0][0].Assignee != "me" {
+		t.Errorf("changing a returned filter changed the cached one: %+v", again)
+	}
+
+	if _, ok := cache.get(key, now.Add(time.Minute)); ok {
+		t.Error("get after TTL: entry is still returned")
+	}
+	if len(cache.entries) != 0 {
+		t.Errorf("expired entry was not removed: %d entries", len(cache.entries))
+	}
+}
+
+// TestAIFilterCacheLimits - При AI_CACHE_TTL=0 ничего не кэшируется, а переполненный кэш
+// освобождает место только за счет устаревших записей
+func TestAIFilterCacheLimits(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	disabled := &aiFilterCache{ttl: 0, size: 10, entries: make(map[string]aiCacheEntry)}
+	disabled.put("a", TaskFilter{}, now)
+	if len(disabled.entries) != 0 {
+		t.Error("cache with zero TTL stored an entry")
+	}
+
+	cache := &aiFilterCache{ttl: time.Minute, size: 2, entries: make(map[string]aiCacheEntry)}
+	cache.put("a", TaskFilter{}, now)
+	cache.put("b", TaskFilter{}, now.Add(30*time.Second))
+	cache.put("c", TaskFilter{}, now.Add(40*time.Second))
+	if _, ok := cache.entries["c"]; ok {
+		t.Error("full cache without expired entries stored a new one")
+	}
+	cache.put("c", TaskFilter{}, now.Add(time.Minute))
+	if _, ok := cache.entries["a"]; ok {
+		t.Error("expired entry a was not evicted")
+	}
+	if _, ok := cache.entries["c"]; !ok {
+		t.Error("entry c was not stored after eviction")
+	}
+}
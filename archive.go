@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- Архив задач ---
+
+// setArchived - Поместить задачу в архив или вернуть из него
+func setArchived(c *gin.Context, archived bool) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	tx := txFromContext(c)
+	var task Task
+	if result := tx.First(&task, id); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	task.ArchivedAt = nil
+	if archived {
+		now := time.Now()
+		task.ArchivedAt = &now
+	}
+	if result := tx.Model(&task).Select("archived_at", "updated_at", "last_activity_at").Updates(&task); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task"})
+		return
+	}
+	enrichTask(c, &task)
+	c.JSON(http.StatusOK, task)
+}
+
+// ArchiveTask - Поместить задачу в архив (POST /tasks/:id/archive)
+// Архивные задачи видны через ?filter=archived и не попадают в active, completed и overdue.
+func ArchiveTask(c *gin.Context) {
+	setArchived(c, true)
+}
+
+// UnarchiveTask - Вернуть задачу из архива (POST /tasks/:id/unarchive)
+func UnarchiveTask(c *gin.Context) {
+	setArchived(c, false)
+}
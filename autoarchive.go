@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// --- Автоархивация завершенных задач ---
+
+// autoArchiveDays - Через сколько дней после завершения задача уходит в архив (AUTO_ARCHIVE_DAYS, 0 - выключено)
+var autoArchiveDays = 0
+
+// autoArchiveUserDays - Персональные сроки по автору задачи (AUTO_ARCHIVE_USER_DAYS="alice=7,bob=0")
+// Для пользователей из списка общий срок не применяется, 0 отключает автоархивацию для пользователя.
+var autoArchiveUserDays = map[string]int{}
+
+// parseAutoArchiveUserDays - Разобрать AUTO_ARCHIVE_USER_DAYS
+func parseAutoArchiveUserDays(value string) (map[string]int, error) {
+	days := make(map[string]int)
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		user, count, found := strings.Cut(pair, "=")
+		user = strings.TrimSpace(user)
+		n, err := strconv.Atoi(strings.TrimSpace(count))
+		if !found || user == "" || err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid entry %q, expected user=days", pair)
+		}
+		days[user] = n
+	}
+	return days, nil
+}
+
+// autoArchiveEnabled - Настроен ли хотя бы один срок автоархивации
+func autoArchiveEnabled() bool {
+	if autoArchiveDays > 0 {
+		return true
+	}
+	for _, days := range autoArchiveUserDays {
+		if days > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// archiveCompletedBefore - Поместить в архив завершенные до cutoff задачи, подходящие под scope
+func archiveCompletedBefore(tx *gorm.DB, now, cutoff time.Time, scope func(*gorm.DB) *gorm.DB) (int64, error) {
+	query := tx.Model(&Task{}).Where("is_completed AND archived_at IS NULL AND completed_at < ?", cutoff)
+	result := scope(query).UpdateColumn("archived_at", now)
+	return result.RowsAffected, result.Error
+}
+
+// archiveCompletedTasks - Поместить в архив задачи, завершенные раньше настроенного срока
+// Обновление идет одним запросом на каждый срок, поэтому хуки и история не срабатывают.
+func archiveCompletedTasks(tx *gorm.DB) (int64, error) {
+	now := time.Now()
+	var archived int64
+	users := make([]string, 0, len(autoArchiveUserDays))
+	for user, days := range autoArchiveUserDays {
+		users = append(users, user)
+		if days == 0 {
+			continue
+		}
+		n, err := archiveCompletedBefore(tx, now, now.AddDate(0, 0, -days), func(q *gorm.DB) *gorm.DB {
+			return q.Where("created_by = ?", user)
+		})
+		archived += n
+		if err != nil {
+			return archived, err
+		}
+	}
+	if autoArchiveDays > 0 {
+		n, err := archiveCompletedBefore(tx, now, now.AddDate(0, 0, -autoArchiveDays), func(q *gorm.DB) *gorm.DB {
+			if len(users) == 0 {
+				return q
+			}
+			return q.Where("created_by IS NULL OR created_by NOT IN ?", users)
+		})
+		archived += n
+		if err != nil {
+			return archived, err
+		}
+	}
+	if archived > 0 {
+		invalidateTaskCachesOnCommit(tx.Statement.Context)
+	}
+	return archived, nil
+}
+
+// runAutoArchiver - Периодически архивировать старые завершенные задачи (интервал AUTO_ARCHIVE_INTERVAL)
+func runAutoArchiver() {
+	ticker := time.NewTicker(getEnvDuration("AUTO_ARCHIVE_INTERVAL", time.Hour))
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if readOnly.Load() {
+			continue
+		}
+		archived, err := archiveCompletedTasks(db)
+		if err != nil {
+			log.Printf("Auto-archive failed: %v", err)
+			continue
+		}
+		if archived > 0 {
+			log.Printf("Auto-archive moved %d completed task(s) to the archive", archived)
+		}
+	}
+}
+
+// AutoArchiveTasks - Ручной запуск автоархивации (POST /tasks/auto-archive, только для администратора)
+func AutoArchiveTasks(c *gin.Context) {
+	if !autoArchiveEnabled() {
+		c.JSON(http.StatusConflict, gin.H{"error": "Auto-archive is not configured (set AUTO_ARCHIVE_DAYS or AUTO_ARCHIVE_USER_DAYS)"})
+		return
+	}
+	archived, err := archiveCompletedTasks(txFromContext(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to archive completed tasks"})
+		return
+	}
+	log.Printf("Manual auto-archive moved %d completed task(s) to the archive", archived)
+	c.JSON(http.StatusOK, gin.H{"archived": archived})
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// --- Канбан-доска ---
+
+// boardColumns - Порядок колонок доски
+var boardColumns = []string{StatusTodo, StatusInProgress, StatusBlocked, StatusDone}
+
+// BoardColumn - Колонка доски: задачи одного статуса по порядку position
+type BoardColumn struct {
+	Status string `json:"status"`
+	Tasks  []Task `json:"tasks"`
+}
+
+// GetBoard - Задачи, разложенные по колонкам статусов (GET /tasks/board)
+// По умолчанию - задачи верхнего уровня, ?parentId= - подзадачи указанной задачи.
+// Поддерживает те же фильтры, что и GET /tasks. Выборка - один запрос, раскладка по колонкам - в памяти.
+func GetBoard(c *gin.Context) {
+	filter, err := parseTaskFilter(c.Request.URL.Query())
+	if err == nil {
+		err = resolveAssignee(c, &filter)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var parentID *uint
+	if raw := c.Query("parentId"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parentId value"})
+			return
+		}
+		parent := uint(id)
+		parentID = &parent
+	}
+
+	var tasks []Task
+	query := applyTaskFilter(siblings(txFromContext(c), parentID), filter)
+	if result := query.Order("position").Order("id").Find(&tasks); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load tasks"})
+		return
+	}
+	enrichTasks(c, tasks)
+
+	columns := make([]BoardColumn, len(boardColumns))
+	index := make(map[string]int, len(boardColumns))
+	for i, status := range boardColumns {
+		columns[i] = BoardColumn{Status: status, Tasks: []Task{}}
+		index[status] = i
+	}
+	for _, task := range tasks {
+		task.toListView()
+		i := index[task.Status]
+		columns[i].Tasks = append(columns[i].Tasks, task)
+	}
+	c.JSON(http.StatusOK, gin.H{"columns": columns})
+}
+
+// ReorderTask - Переставить задачу среди соседей и/или перенести в другую колонку
+// (POST /tasks/:id/reorder). Тело: {"position": 2, "status": "in_progress"}, оба поля необязательны.
+// position - место среди всех подзадач того же родителя; остальные задачи сдвигаются.
+// Как и move-to, работает под блокировкой списка соседей (см. lockForMove).
+func ReorderTask(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var requestBody struct {
+		Position *int    `json:"position"`
+		Status   *string `json:"status"`
+	}
+	if err := bindJSON(c, &requestBody); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if requestBody.Position == nil && requestBody.Status == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "position or status is required"})
+		return
+	}
+	if requestBody.Position != nil && *requestBody.Position < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "position must not be negative"})
+		return
+	}
+
+	var task Task
+	var affected []uint
+	err := txFromContext(c).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if affected, err = lockForMove(tx, id, func(current *uint) *uint { return current }, &task); err != nil {
+			return err
+		}
+		if requestBody.Status != nil {
+			task.Status = *requestBody.Status
+			if err := validateTask(tx, &task); err != nil {
+				return err
+			}
+		}
+		if requestBody.Position != nil {
+			if err := placeTask(tx, &task, task.ParentID, task.Position, *requestBody.Position); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&task).Select("position", "status", "is_completed", "completed_at", "updated_at", "last_activity_at").Updates(&task).Error
+	})
+	if err != nil {
+		respondMoveError(c, err)
+		return
+	}
+	invalidateTaskCachesOnCommit(c.Request.Context(), affected...) // Соседи сдвинуты UpdateColumn без хуков
+	enrichTask(c, &task)
+	c.JSON(http.StatusOK, task)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// TestReorderTaskConcurrent - Одновременные перестановки в одной колонке не дают одинаковых позиций
+func TestReorderTaskConcurrent(t *testing.T) {
+	setupTestDB(t)
+	const count = 5
+	ids := make([]uint, count)
+	for i := range ids {
+		ids[i] = createTestTask(t, Task{Title: fmt.Sprintf("Карточка %d", i), Position: i}).ID
+	}
+
+	var wg sync.WaitGroup
+	statuses := make(chan int, 4*count)
+	for i := 0; i < 4*count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"position": %d}`, (i*3)%count)
+			statuses <- performRequest(http.MethodPost, fmt.Sprintf("/tasks/%d/reorder", ids[i%count]), body, "").Code
+		}(i)
+	}
+	wg.Wait()
+	close(statuses)
+	for status := range statuses {
+		if status != http.StatusOK {
+			t.Errorf("reorder status = %d, want 200", status)
+		}
+	}
+
+	var positions []int
+	if err := db.Model(&Task{}).Where("parent_id IS NULL").Order("position").Pluck("position", &positions).Error; err != nil {
+		t.Fatal(err)
+	}
+	for i, position := range positions {
+		if position != i {
+			t.Fatalf("positions after concurrent reorders = %v, want 0..%d without repeats", positions, count-1)
+		}
+	}
+}
+
+// TestReorderTaskValidationError - Недопустимый статус возвращается в общем формате ошибок проверки
+func TestReorderTaskValidationError(t *testing.T) {
+	setupTestDB(t)
+	task := createTestTask(t, Task{Title: "Заблокирована", Status: StatusBlocked})
+
+	w := performRequest(http.MethodPost, fmt.Sprintf("/tasks/%d/reorder", task.ID), `{"status": "done"}`, "")
+	expectStatus(t, w, http.StatusBadRequest)
+	var body struct {
+		Code   string            `json:"code"`
+		Errors map[string]string `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code != "validation_failed" || body.Errors["status"] == "" {
+		t.Errorf("reorder validation error = %s, want code validation_failed with errors.status", w.Body.String())
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- Отладочное логирование тел запросов и ответов ---
+
+// redactedValue - Чем заменяются секреты в логах
+const redactedValue = "[REDACTED]"
+
+// redactedHeaders - Заголовки, значения которых не пишутся в лог
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"X-Admin-Token":       true,
+	"X-Api-Key":           true,
+}
+
+// secretFieldPattern - Строковые поля JSON с секретами: "password": "..." и т.п.
+// Работает по тексту, поэтому маскирует и в теле, обрезанном до LOG_BODY_MAX_BYTES.
+var secretFieldPattern = regexp.MustCompile(`(?i)("[^"]*(?:password|secret|token|apikey|api_key|authorization)[^"]*"\s*:\s*)"(?:[^"\\]|\\.)*"?`)
+
+// aiQueryFieldPattern - Текст запроса к ИИ ("query"), маскируется при LOG_BODIES_REDACT_AI_QUERY=true
+var aiQueryFieldPattern = regexp.MustCompile(`("query"\s*:\s*)"(?:[^"\\]|\\.)*"?`)
+
+// redactBody - Замаскировать секреты (и, если нужно, запрос к ИИ) в теле для лога
+func redactBody(body []byte, redactAIQuery bool) string {
+	body = secretFieldPattern.ReplaceAll(body, []byte(`${1}"`+redactedValue+`"`))
+	if redactAIQuery {
+		body = aiQueryFieldPattern.ReplaceAll(body, []byte(`${1}"`+redactedValue+`"`))
+	}
+	return string(body)
+}
+
+// redactHeaders - Заголовки запроса для лога с замаскированными секретами
+func redactHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for name, values := range header {
+		if redactedHeaders[name] {
+			headers[name] = redactedValue
+		} else {
+			headers[name] = strings.Join(values, ", ")
+		}
+	}
+	return headers
+}
+
+// bodyLogWriter - Пропускает ответ клиенту без изменений и копирует первые limit байт для лога
+type bodyLogWriter struct {
+	gin.ResponseWriter
+	body  bytes.Buffer
+	limit int
+	size  int
+}
+
+func (w *bodyLogWriter) capture(data []byte) {
+	w.size += len(data)
+	if room := w.limit - w.body.Len(); room > 0 {
+		w.body.Write(data[:min(room, len(data))])
+	}
+}
+
+func (w *bodyLogWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyLogWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// bodyLogger - Запись тел запросов и ответов на уровне DEBUG для разбора проблем клиентов
+// Включается LOG_BODIES=true и пишет только при LOG_LEVEL=debug; при APP_ENV=production не
+// включается никогда. Тело обрезается до LOG_BODY_MAX_BYTES (4096), секреты в заголовках и
+// полях JSON маскируются, запрос к ИИ - только при LOG_BODIES_REDACT_AI_QUERY=true.
+func bodyLogger() gin.HandlerFunc {
+	enabled := getEnvBool("LOG_BODIES", false)
+	if enabled && os.Getenv("APP_ENV") == "production" {
+		log.Println("LOG_BODIES is ignored when APP_ENV=production")
+		enabled = false
+	}
+	limit := getEnvInt("LOG_BODY_MAX_BYTES", 4096)
+	if limit < 0 {
+		log.Fatalf("LOG_BODY_MAX_BYTES must not be negative, got %d", limit)
+	}
+	redactAIQuery := getEnvBool("LOG_BODIES_REDACT_AI_QUERY", false)
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		if !enabled || !slog.Default().Enabled(ctx, slog.LevelDebug) {
+			c.Next()
+			return
+		}
+
+		// Читаются только первые limit+1 байт; остаток тела обработчик дочитает из исходного потока
+		var request []byte
+		if c.Request.Body != nil {
+			request, _ = io.ReadAll(io.LimitReader(c.Request.Body, int64(limit)+1))
+			c.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(request), c.Request.Body), c.Request.Body}
+		}
+		requestTruncated := len(request) > limit
+		if requestTruncated {
+			request = request[:limit]
+		}
+		slog.DebugContext(ctx, "request body",
+			"method", c.Request.Method, "path", c.Request.URL.RequestURI(),
+			"headers", redactHeaders(c.Request.Header),
+			"body", redactBody(request, redactAIQuery), "truncated", requestTruncated,
+			"requestId", requestIDFrom(c))
+
+		writer := &bodyLogWriter{ResponseWriter: c.Writer, limit: limit}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		slog.DebugContext(ctx, "response body",
+			"method", c.Request.Method, "path", c.Request.URL.Path, "status", writer.Status(),
+			"body", redactBody(writer.body.Bytes(), redactAIQuery), "size", writer.size, "truncated", writer.size > limit,
+			"requestId", requestIDFrom(c))
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRedactBody(t *testing.T) {
+	tests := []struct {
+		body          string
+		redactAIQuery bool
+		want          string
+	}{
+		{`{"password": "hunter2", "title": "x"}`, false, `{"password": "[REDACTED]", "title": "x"}`},
+		{`{"apiKey":"abc","Secret_Value":"s\"q"}`, false, `{"apiKey":"[REDACTED]","Secret_Value":"[REDACTED]"}`},
+		{`{"title": "ok", "accessToken": "abc`, false, `{"title": "ok", "accessToken": "[REDACTED]"`},
+		{`{"query": "мои задачи"}`, false, `{"query": "мои задачи"}`},
+		{`{"query": "мои задачи"}`, true, `{"query": "[REDACTED]"}`},
+	}
+	for _, tt := range tests {
+		if got := redactBody([]byte(tt.body), tt.redactAIQuery); got != tt.want {
+			t.Errorf("redactBody(%s, %v) = %s, want %s", tt.body, tt.redactAIQuery, got, tt.want)
+		}
+	}
+}
+
+func TestRedactHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer secret")
+	header.Set("X-Admin-Token", "admin")
+	header.Add("Accept", "application/json")
+	header.Add("Accept", "text/csv")
+
+	got := redactHeaders(header)
+	if got["Authorization"] != redactedValue || got["X-Admin-Token"] != redactedValue {
+		t.Errorf("secret headers = %q, %q, want %s", got["Authorization"], got["X-Admin-Token"], redactedValue)
+	}
+	if got["Accept"] != "application/json, text/csv" {
+		t.Errorf("Accept = %q, want both values joined", got["Accept"])
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multiStatus - Тело ответа 207 пакетной операции
+type multiStatus struct {
+	Updated int              `json:"updated"`
+	Deleted int              `json:"deleted"`
+	Failed  int              `json:"failed"`
+	Results []BulkItemResult `json:"results"`
+}
+
+// decodeMultiStatus - Проверить статус 207 и разобрать тело ответа
+func decodeMultiStatus(t *testing.T, w *httptest.ResponseRecorder) multiStatus {
+	t.Helper()
+	expectStatus(t, w, http.StatusMultiStatus)
+	var body multiStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode 207 body: %v", err)
+	}
+	return body
+}
+
+func TestBatchMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		query, def string
+		want       string
+		ok         bool
+	}{
+		{query: "", def: batchAtomic, want: batchAtomic, ok: true},
+		{query: "", def: "", want: "", ok: true},
+		{query: "mode=partial", def: batchAtomic, want: batchPartial, ok: true},
+		{query: "mode=atomic", def: "", want: batchAtomic, ok: true},
+		{query: "mode=all", def: batchAtomic, ok: false},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/tasks/import?"+tt.query, nil)
+		got, ok := batchMode(c, tt.def)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("batchMode(%q, %q) = %q, %v; want %q, %v", tt.query, tt.def, got, ok, tt.want, tt.ok)
+		}
+		if !ok && w.Code != http.StatusBadRequest {
+			t.Errorf("batchMode(%q): status %d, want 400", tt.query, w.Code)
+		}
+	}
+}
+
+// TestBulkUpdateModes - По умолчанию ошибка одной задачи откатывает все изменения,
+// а ?mode=partial применяет их к остальным и отвечает 207 со статусом каждой задачи
+func TestBulkUpdateModes(t *testing.T) {
+	setupTestDB(t)
+	first := createTestTask(t, Task{Title: "Первая"})
+	second := createTestTask(t, Task{Title: "Вторая"})
+	body := fmt.Sprintf(`{"ids": [%d, 999999, %d], "changes": {"title": "Новое название"}}`, first.ID, second.ID)
+
+	w := performRequest(http.MethodPatch, "/tasks/bulk", body, "")
+	if w.Code == http.StatusOK || w.Code == http.StatusMultiStatus {
+		t.Fatalf("atomic bulk update with a duplicate title: status %d", w.Code)
+	}
+	var count int64
+	db.Model(&Task{}).Where("title = ?", "Новое название").Count(&count)
+	if count != 0 {
+		t.Errorf("atomic bulk update left %d renamed tasks", count)
+	}
+
+	got := decodeMultiStatus(t, performRequest(http.MethodPatch, "/tasks/bulk?mode=partial", body, ""))
+	if got.Updated != 1 || got.Failed != 2 || len(got.Results) != 3 {
+		t.Fatalf("partial bulk update = %+v, want 1 updated and 2 failed", got)
+	}
+	want := []BulkItemResult{
+		{ID: first.ID, Status: http.StatusOK},
+		{ID: 999999, Status: http.StatusNotFound},
+		{ID: second.ID, Status: http.StatusConflict, Code: "duplicate_title"},
+	}
+	for i, result := range got.Results {
+		if result.ID != want[i].ID || result.Status != want[i].Status || result.Code != want[i].Code {
+			t.Errorf("result %d = %+v, want id %d, status %d, code %q", i, result, want[i].ID, want[i].Status, want[i].Code)
+		}
+	}
+	var renamed Task
+	if err := db.First(&renamed, first.ID).Error; err != nil || renamed.Title != "Новое название" {
+		t.Errorf("first task after partial update: %q, %v", renamed.Title, err)
+	}
+	if err := db.First(&renamed, second.ID).Error; err != nil || renamed.Title != "Вторая" {
+		t.Errorf("second task after partial update: %q, %v", renamed.Title, err)
+	}
+
+	w = performRequest(http.MethodPatch, "/tasks/bulk?mode=some", body, "")
+	expectStatus(t, w, http.StatusBadRequest)
+}
+
+// TestImportModes - ?mode=atomic не создает ни одной задачи, если хоть одна строка с ошибкой;
+// ?mode=partial создает остальные и отвечает 207
+func TestImportModes(t *testing.T) {
+	setupTestDB(t)
+	body := `{"tasks": [{"title": "Импорт 1"}, {"title": ""}, {"title": "Импорт 2"}]}`
+
+	w := performRequest(http.MethodPost, "/tasks/import?mode=atomic", body, "")
+	expectStatus(t, w, http.StatusBadRequest)
+	var failed struct {
+		Code    string            `json:"code"`
+		Results []ImportRowResult `json:"results"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &failed); err != nil {
+		t.Fatalf("decode atomic import: %v", err)
+	}
+	if failed.Code != "import_failed" || len(failed.Results) != 3 || failed.Results[0].Error == "" || failed.Results[2].Error == "" {
+		t.Errorf("atomic import = %+v, want every row reported as not imported", failed)
+	}
+	var count int64
+	db.Model(&Task{}).Count(&count)
+	if count != 0 {
+		t.Fatalf("atomic import left %d tasks", count)
+	}
+
+	w = performRequest(http.MethodPost, "/tasks/import?mode=partial", body, "")
+	expectStatus(t, w, http.StatusMultiStatus)
+	var partial struct {
+		Created int `json:"created"`
+		Skipped int `json:"skipped"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &partial); err != nil {
+		t.Fatalf("decode partial import: %v", err)
+	}
+	if partial.Created != 2 || partial.Skipped != 1 {
+		t.Errorf("partial import = %+v, want 2 created and 1 skipped", partial)
+	}
+	db.Model(&Task{}).Count(&count)
+	if count != 2 {
+		t.Errorf("tasks after partial import = %d, want 2", count)
+	}
+}
+
+// TestDeleteCompletedPartial - ?mode=partial удаляет завершенные задачи пользователя по одной
+// и отвечает 207; незавершенные и чужие задачи, даже назначенные ему, остаются
+func TestDeleteCompletedPartial(t *testing.T) {
+	setupTestDB(t)
+	done := createTestTask(t, Task{Title: "Готово", Status: StatusDone, IsCompleted: true, CreatedBy: stringPtr("alice")})
+	open := createTestTask(t, Task{Title: "В работе", CreatedBy: stringPtr("alice")})
+	foreign := createTestTask(t, Task{Title: "Чужая", Status: StatusDone, IsCompleted: true, CreatedBy: stringPtr("bob"), AssigneeID: stringPtr("alice")})
+
+	got := decodeMultiStatus(t, performRequest(http.MethodDelete, "/tasks/completed?confirm=true&mode=partial", "", "alice"))
+	if got.Deleted != 1 || got.Failed != 0 || len(got.Results) != 1 || got.Results[0].ID != done.ID || got.Results[0].Status != http.StatusOK {
+		t.Fatalf("partial delete = %+v, want only task %d deleted", got, done.ID)
+	}
+	var left []uint
+	db.Model(&Task{}).Order("id").Pluck("id", &left)
+	if len(left) != 2 || left[0] != open.ID || left[1] != foreign.ID {
+		t.Errorf("tasks after partial delete = %v, want %d and %d", left, open.ID, foreign.ID)
+	}
+}
+
+// TestBulkStatusKeepsCompletedAt - Массовый перевод в done не меняет время завершения
+// у уже завершенных задач и ставит его только открытым
+func TestBulkStatusKeepsCompletedAt(t *testing.T) {
+	setupTestDB(t)
+	completedAt := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	done := createTestTask(t, Task{Title: "Уже готово", Status: StatusDone, IsCompleted: true, CompletedAt: &completedAt})
+	open := createTestTask(t, Task{Title: "Открыта"})
+
+	before := time.Now()
+	body := fmt.Sprintf(`{"ids": [%d, %d], "changes": {"status": "done"}}`, done.ID, open.ID)
+	w := performRequest(http.MethodPatch, "/tasks/bulk", body, "")
+	expectStatus(t, w, http.StatusOK)
+
+	var got Task
+	if err := db.First(&got, done.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.CompletedAt == nil || !got.CompletedAt.Equal(completedAt) {
+		t.Errorf("already done task: completedAt = %v, want %v", got.CompletedAt, completedAt)
+	}
+	if err := db.First(&got, open.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !got.IsCompleted || got.CompletedAt == nil || got.CompletedAt.Before(before.Add(-time.Second)) {
+		t.Errorf("newly done task: isCompleted %v, completedAt %v", got.IsCompleted, got.CompletedAt)
+	}
+}
+
+// TestUpdateTasksByFilter - PATCH /tasks меняет только задачи под фильтром и требует
+// confirm=true и хотя бы одно условие
+func TestUpdateTasksByFilter(t *testing.T) {
+	setupTestDB(t)
+	high := createTestTask(t, Task{Title: "Важная", Priority: PriorityHigh})
+	low := createTestTask(t, Task{Title: "Неважная", Priority: PriorityLow})
+	body := `{"tags": "разобрано"}`
+
+	w := performRequest(http.MethodPatch, "/tasks/?priority=high", body, "")
+	expectStatus(t, w, http.StatusBadRequest)
+	if !strings.Contains(w.Body.String(), "confirmation_required") {
+		t.Errorf("without confirm: %s, want code confirmation_required", w.Body.String())
+	}
+	w = performRequest(http.MethodPatch, "/tasks/?confirm=true", body, "")
+	expectStatus(t, w, http.StatusBadRequest)
+	if !strings.Contains(w.Body.String(), "unbounded_update") {
+		t.Errorf("without a filter: %s, want code unbounded_update", w.Body.String())
+	}
+
+	w = performRequest(http.MethodPatch, "/tasks/?priority=high&confirm=true", body, "")
+	expectStatus(t, w, http.StatusOK)
+	var got struct {
+		Updated int64 `json:"updated"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || got.Updated != 1 {
+		t.Errorf("updated = %d (%v), want 1", got.Updated, err)
+	}
+	for _, tt := range []struct {
+		id   uint
+		tags string
+	}{{high.ID, "разобрано"}, {low.ID, ""}} {
+		var task Task
+		if err := db.First(&task, tt.id).Error; err != nil || task.Tags != tt.tags {
+			t.Errorf("task %d tags = %q (%v), want %q", tt.id, task.Tags, err, tt.tags)
+		}
+	}
+}
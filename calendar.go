@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- Календарные представления (сегодня, сводка по неделе) ---
+
+// Первый день недели для недельных окон (WEEK_START: monday или sunday)
+var weekStart = time.Monday
+
+// parseWeekStart - Разобрать значение WEEK_START
+func parseWeekStart(raw string) time.Weekday {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", "monday", "mon":
+		return time.Monday
+	case "sunday", "sun":
+		return time.Sunday
+	default:
+		log.Printf("Invalid WEEK_START value %q, using monday", raw)
+		return time.Monday
+	}
+}
+
+// requestLocation - Часовой пояс запроса: ?tz= (IANA, например Asia/Almaty),
+// затем пояс из настроек пользователя (PUT /me/settings), по умолчанию - пояс сервера
+func requestLocation(c *gin.Context) (*time.Location, error) {
+	name := c.Query("tz")
+	if name == "" {
+		if loc := userLocation(c); loc != nil {
+			return loc, nil
+		}
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return loc, nil
+}
+
+// startOfDay - Полночь дня, в который попадает t, в поясе loc
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// weekBounds - Начало и конец (не включительно) недели, содержащей t, с учетом WEEK_START
+func weekBounds(t time.Time, loc *time.Location) (time.Time, time.Time) {
+	day := startOfDay(t, loc)
+	offset := (int(day.Weekday()) - int(weekStart) + 7) % 7
+	start := day.AddDate(0, 0, -offset)
+	return start, start.AddDate(0, 0, 7)
+}
+
+// GetTodayTasks - Незавершенные задачи не из архива со сроком на сегодня (в часовом поясе ?tz=)
+func GetTodayTasks(c *gin.Context) {
+	loc, err := requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	today := startOfDay(time.Now(), loc)
+
+	var tasks []Task
+	result := whereDueBetween(txFromContext(c), today, today.AddDate(0, 0, 1)).Order("due_date, id").Find(&tasks)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load tasks"})
+		return
+	}
+	enrichTasks(c, tasks)
+	writeListResponse(c, tasks, ListMeta{Total: int64(len(tasks)), PageSize: len(tasks)})
+}
+
+// GetDigest - Сводка: просроченные, на сегодня, на остаток недели и завершенные за неделю
+// Границы дня и недели считаются в часовом поясе ?tz= с учетом WEEK_START.
+func GetDigest(c *gin.Context) {
+	loc, err := requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	now := time.Now()
+	today := startOfDay(now, loc)
+	tomorrow := today.AddDate(0, 0, 1)
+	weekFrom, weekTo := weekBounds(now, loc)
+
+	tx := txFromContext(c)
+	var overdue, dueToday, dueThisWeek []Task
+	if err := whereOverdue(tx, today).Order("due_date, id").Find(&overdue).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load tasks"})
+		return
+	}
+	if err := whereDueBetween(tx, today, tomorrow).Order("due_date, id").Find(&dueToday).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load tasks"})
+		return
+	}
+	if err := whereDueBetween(tx, tomorrow, weekTo).Order("due_date, id").Find(&dueThisWeek).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load tasks"})
+		return
+	}
+
+	// По времени завершения: правка давно завершенной задачи не должна засчитывать ее снова
+	var completedThisWeek int64
+	if err := tx.Model(&Task{}).Where("is_completed AND completed_at >= ? AND completed_at < ?", weekFrom, weekTo).Count(&completedThisWeek).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load tasks"})
+		return
+	}
+
+	enrichTasks(c, overdue)
+	enrichTasks(c, dueToday)
+	enrichTasks(c, dueThisWeek)
+	c.JSON(http.StatusOK, gin.H{
+		"timezone":          loc.String(),
+		"weekStart":         weekFrom,
+		"weekEnd":           weekTo,
+		"overdue":           overdue,
+		"today":             dueToday,
+		"thisWeek":          dueThisWeek,
+		"completedThisWeek": completedThisWeek,
+	})
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestDigestCompletedThisWeekByCompletedAt - Завершенные за неделю считаются по completedAt, а не по updatedAt
+func TestDigestCompletedThisWeekByCompletedAt(t *testing.T) {
+	setupTestDB(t)
+	now := time.Now()
+	longAgo := now.AddDate(0, -2, 0)
+	createTestTask(t, Task{Title: "Done now", Status: StatusDone, IsCompleted: true, CompletedAt: &now})
+	// Завершена давно, но только что отредактирована: updated_at текущий
+	createTestTask(t, Task{Title: "Done long ago", Status: StatusDone, IsCompleted: true, CompletedAt: &longAgo})
+
+	w := performRequest(http.MethodGet, "/tasks/digest?tz=UTC", "", "")
+	expectStatus(t, w, http.StatusOK)
+	var digest struct {
+		CompletedThisWeek int64 `json:"completedThisWeek"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &digest); err != nil {
+		t.Fatalf("decode digest: %v", err)
+	}
+	if digest.CompletedThisWeek != 1 {
+		t.Errorf("completedThisWeek = %d, want 1", digest.CompletedThisWeek)
+	}
+}
+
+// TestArchivedTasksNotOverdueOrToday - Задачи в архиве не попадают в просроченные и задачи на сегодня
+// ни в списке, ни в дайджесте, ни в сводке просрочки
+func TestArchivedTasksNotOverdueOrToday(t *testing.T) {
+	setupTestDB(t)
+	now := time.Now()
+	past := now.AddDate(0, 0, -3)
+	noon := startOfDay(now, time.UTC).Add(12 * time.Hour)
+	overdue := createTestTask(t, Task{Title: "Просрочена", DueDate: &past})
+	today := createTestTask(t, Task{Title: "Сегодня", DueDate: &noon})
+	createTestTask(t, Task{Title: "Просрочена в архиве", DueDate: &past, ArchivedAt: &now})
+	createTestTask(t, Task{Title: "Сегодня в архиве", DueDate: &noon, ArchivedAt: &now})
+
+	w := performRequest(http.MethodGet, "/tasks/today?tz=UTC", "", "")
+	expectStatus(t, w, http.StatusOK)
+	if got := responseTaskIDs(t, w); len(got) != 1 || got[0] != today.ID {
+		t.Errorf("today = %v, want only %d", got, today.ID)
+	}
+
+	w = performRequest(http.MethodGet, "/tasks/?filter=overdue", "", "")
+	expectStatus(t, w, http.StatusOK)
+	if got := responseTaskIDs(t, w); len(got) != 1 || got[0] != overdue.ID {
+		t.Errorf("filter=overdue = %v, want only %d", got, overdue.ID)
+	}
+
+	w = performRequest(http.MethodGet, "/tasks/digest?tz=UTC", "", "")
+	expectStatus(t, w, http.StatusOK)
+	var digest struct {
+		Overdue []Task `json:"overdue"`
+		Today   []Task `json:"today"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &digest); err != nil {
+		t.Fatalf("decode digest: %v", err)
+	}
+	if len(digest.Overdue) != 1 || digest.Overdue[0].ID != overdue.ID || len(digest.Today) != 1 || digest.Today[0].ID != today.ID {
+		t.Errorf("digest overdue = %v, today = %v; want only %d and %d", digest.Overdue, digest.Today, overdue.ID, today.ID)
+	}
+
+	w = performRequest(http.MethodGet, "/tasks/overdue/summary?tz=UTC", "", "")
+	expectStatus(t, w, http.StatusOK)
+	var summary struct {
+		Total int64 `json:"total"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &summary); err != nil {
+		t.Fatalf("decode overdue summary: %v", err)
+	}
+	if summary.Total != 1 {
+		t.Errorf("overdue summary total = %d, want 1", summary.Total)
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// --- Чек-листы задач ---
+
+// ChecklistItem - Пункт чек-листа внутри задачи (легче полноценной подзадачи)
+type ChecklistItem struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TaskID    uint      `json:"taskId" gorm:"index"`
+	Text      string    `json:"text"`
+	Done      bool      `json:"done"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Максимальная длина текста пункта в символах
+const maxChecklistTextLength = 500
+
+// attachChecklists - Загрузить чек-листы для списка задач одним запросом и посчитать прогресс
+func attachChecklists(tx *gorm.DB, tasks []Task) {
+	if len(tasks) == 0 {
+		return
+	}
+	ids := make([]uint, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	var items []ChecklistItem
+	if err := tx.Where("task_id IN ?", ids).Order("task_id, position, id").Find(&items).Error; err != nil {
+		log.Printf("Failed to load checklists: %v", err)
+		return
+	}
+	byTask := make(map[uint][]ChecklistItem)
+	for _, item := range items {
+		byTask[item.TaskID] = append(byTask[item.TaskID], item)
+	}
+	for i := range tasks {
+		checklist := byTask[tasks[i].ID]
+		if len(checklist) == 0 {
+			continue
+		}
+		done := 0
+		for _, item := range checklist {
+			if item.Done {
+				done++
+			}
+		}
+		progress := float64(done) / float64(len(checklist))
+		tasks[i].Checklist = checklist
+		tasks[i].ChecklistProgress = &progress
+	}
+}
+
+// findChecklistItem - Найти пункт :itemId задачи :id; при ошибке ответ уже отправлен
+func findChecklistItem(c *gin.Context) (ChecklistItem, bool) {
+	var item ChecklistItem
+	taskID, ok := parseID(c)
+	if !ok {
+		return item, false
+	}
+	itemID, err := strconv.ParseUint(c.Param("itemId"), 10, 64)
+	if err != nil || itemID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid checklist item id", "code": "invalid_id"})
+		return item, false
+	}
+	if result := txFromContext(c).Where("task_id = ?", taskID).First(&item, itemID); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Checklist item not found"})
+		return item, false
+	}
+	return item, true
+}
+
+// validateChecklistText - Проверить текст пункта
+func validateChecklistText(c *gin.Context, text string) bool {
+	if strings.TrimSpace(text) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "text is required"})
+		return false
+	}
+	if len([]rune(text)) > maxChecklistTextLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "text is too long"})
+		return false
+	}
+	return true
+}
+
+// touchTask - Отметить активность по задаче при изменении ее чек-листа
+func touchTask(tx *gorm.DB, taskID uint) error {
+	invalidateTaskCachesOnCommit(tx.Statement.Context, taskID)
+	return tx.Model(&Task{}).Where("id = ?", taskID).UpdateColumn("last_activity_at", time.Now()).Error
+}
+
+// AddChecklistItem - Добавить пункт в конец чек-листа (POST /tasks/:id/checklist)
+// Тело: {"text": "..."}.
+func AddChecklistItem(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var task Task
+	if result := txFromContext(c).First(&task, id); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	var requestBody struct {
+		Text string `json:"text"`
+	}
+	if err := bindJSON(c, &requestBody); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if !validateChecklistText(c, requestBody.Text) {
+		return
+	}
+
+	item := ChecklistItem{TaskID: task.ID, Text: strings.TrimSpace(requestBody.Text)}
+	err := txFromContext(c).Transaction(func(tx *gorm.DB) error {
+		var maxPosition *int
+		tx.Model(&ChecklistItem{}).Where("task_id = ?", task.ID).Select("MAX(position)").Scan(&maxPosition)
+		if maxPosition != nil {
+			item.Position = *maxPosition + 1
+		}
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		return touchTask(tx, task.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add checklist item"})
+		return
+	}
+	c.JSON(http.StatusCreated, item)
+}
+
+// UpdateChecklistItem - Изменить текст пункта или отметить его (PATCH /tasks/:id/checklist/:itemId)
+// Тело: {"text": "...", "done": true}, оба поля необязательны.
+func UpdateChecklistItem(c *gin.Context) {
+	item, ok := findChecklistItem(c)
+	if !ok {
+		return
+	}
+	var requestBody struct {
+		Text *string `json:"text"`
+		Done *bool   `json:"done"`
+	}
+	if err := bindJSON(c, &requestBody); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if requestBody.Text != nil {
+		if !validateChecklistText(c, *requestBody.Text) {
+			return
+		}
+		item.Text = strings.TrimSpace(*requestBody.Text)
+	}
+	if requestBody.Done != nil {
+		item.Done = *requestBody.Done
+	}
+
+	err := txFromContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&item).Select("text", "done", "updated_at").Updates(&item).Error; err != nil {
+			return err
+		}
+		return touchTask(tx, item.TaskID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update checklist item"})
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// ReorderChecklist - Задать новый порядок пунктов (POST /tasks/:id/checklist/reorder)
+// Тело: {"ids": [3, 1, 2]} - все пункты чек-листа в нужном порядке.
+func ReorderChecklist(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var requestBody struct {
+		IDs []uint `json:"ids" binding:"required"`
+	}
+	if err := bindJSON(c, &requestBody); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	var items []ChecklistItem
+	if result := txFromContext(c).Where("task_id = ?", id).Find(&items); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load checklist"})
+		return
+	}
+	existing := make(map[uint]bool, len(items))
+	for _, item := range items {
+		existing[item.ID] = true
+	}
+	seen := make(map[uint]bool, len(requestBody.IDs))
+	for _, itemID := range requestBody.IDs {
+		if !existing[itemID] || seen[itemID] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "ids must list every checklist item of the task exactly once"})
+			return
+		}
+		seen[itemID] = true
+	}
+	if len(seen) != len(existing) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ids must list every checklist item of the task exactly once"})
+		return
+	}
+
+	err := txFromContext(c).Transaction(func(tx *gorm.DB) error {
+		for position, itemID := range requestBody.IDs {
+			if err := tx.Model(&ChecklistItem{}).Where("id = ?", itemID).UpdateColumn("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return touchTask(tx, id)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder checklist"})
+		return
+	}
+
+	txFromContext(c).Where("task_id = ?", id).Order("position, id").Find(&items)
+	c.JSON(http.StatusOK, items)
+}
+
+// DeleteChecklistItem - Удалить пункт (DELETE /tasks/:id/checklist/:itemId)
+func DeleteChecklistItem(c *gin.Context) {
+	item, ok := findChecklistItem(c)
+	if !ok {
+		return
+	}
+	err := txFromContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&item).Error; err != nil {
+			return err
+		}
+		// Закрываем дыру в нумерации
+		if err := tx.Model(&ChecklistItem{}).
+			Where("task_id = ? AND position > ?", item.TaskID, item.Position).
+			UpdateColumn("position", gorm.Expr("position - 1")).Error; err != nil {
+			return err
+		}
+		return touchTask(tx, item.TaskID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete checklist item"})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
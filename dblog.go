@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// --- Логирование SQL-запросов ---
+
+// slogGormLogger - Логгер GORM поверх slog
+// Запросы дольше slowThreshold пишутся с уровнем WARN, ошибки - ERROR,
+// остальные - DEBUG (видны только при LOG_LEVEL=debug).
+type slogGormLogger struct {
+	slowThreshold time.Duration
+	level         logger.LogLevel
+}
+
+// newSlogGormLogger - Логгер с порогом медленных запросов DB_SLOW_QUERY_THRESHOLD (по умолчанию 200ms)
+func newSlogGormLogger() *slogGormLogger {
+	return &slogGormLogger{
+		slowThreshold: getEnvDuration("DB_SLOW_QUERY_THRESHOLD", 200*time.Millisecond),
+		level:         logger.Info,
+	}
+}
+
+// initLogLevel - Уровень slog из LOG_LEVEL (debug, info, warn, error)
+func initLogLevel() {
+	raw := os.Getenv("LOG_LEVEL")
+	if raw == "" {
+		return
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(raw)); err != nil {
+		log.Printf("Invalid LOG_LEVEL value %q, using info", raw)
+		return
+	}
+	slog.SetLogLoggerLevel(level)
+}
+
+func (l *slogGormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+func (l *slogGormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Info {
+		slog.InfoContext(ctx, fmt.Sprintf(msg, data...), "requestId", requestIDFromContext(ctx))
+	}
+}
+
+func (l *slogGormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Warn {
+		slog.WarnContext(ctx, fmt.Sprintf(msg, data...), "requestId", requestIDFromContext(ctx))
+	}
+}
+
+func (l *slogGormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Error {
+		slog.ErrorContext(ctx, fmt.Sprintf(msg, data...), "requestId", requestIDFromContext(ctx))
+	}
+}
+
+// Trace - Вызывается GORM после каждого запроса
+func (l *slogGormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= logger.Error:
+		sql, rows := fc()
+		slog.ErrorContext(ctx, "query failed", "error", err, "sql", sql, "rows", rows,
+			"duration", elapsed, "requestId", requestIDFromContext(ctx))
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= logger.Warn:
+		sql, rows := fc()
+		slog.WarnContext(ctx, "slow query", "sql", sql, "rows", rows, "duration", elapsed,
+			"threshold", l.slowThreshold, "requestId", requestIDFromContext(ctx))
+	case slog.Default().Enabled(ctx, slog.LevelDebug):
+		sql, rows := fc()
+		slog.DebugContext(ctx, "query", "sql", sql, "rows", rows, "duration", elapsed,
+			"requestId", requestIDFromContext(ctx))
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// --- Отладка фильтров ---
+
+// ExplainTasks - SQL, который GET /tasks построит для тех же параметров, и план запроса Postgres
+// Доступно только при FEATURE_DEBUG=true. Запрос не выполняется: EXPLAIN без ANALYZE.
+func ExplainTasks(c *gin.Context) {
+	filter, err := parseTaskFilter(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	query, order, ok := taskListQuery(c, filter)
+	if !ok {
+		return
+	}
+	pagination, err := parsePagination(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// DryRun собирает SQL и параметры, не обращаясь к базе
+	var tasks []Task
+	stmt := pageQuery(query.Session(&gorm.Session{DryRun: true}), pagination, order).Find(&tasks).Statement
+	sql := stmt.SQL.String()
+
+	rows, err := txFromContext(c).Raw("EXPLAIN "+sql, stmt.Vars...).Rows()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "EXPLAIN failed: " + err.Error(), "sql": sql})
+		return
+	}
+	defer rows.Close()
+	plan := []string{}
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read query plan"})
+			return
+		}
+		plan = append(plan, line)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sql":          sql,
+		"vars":         stmt.Vars,
+		"interpolated": db.Dialector.Explain(sql, stmt.Vars...),
+		"plan":         plan,
+	})
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"container/heap"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+// --- Зависимости между задачами ---
+
+// TaskDependency - Ребро графа зависимостей: задачу TaskID нужно делать после DependsOnID
+type TaskDependency struct {
+	TaskID      uint      `json:"taskId" gorm:"primaryKey"`
+	DependsOnID uint      `json:"dependsOnId" gorm:"primaryKey"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Максимальное число задач, для которых строится порядок выполнения
+const maxOrderTasks = 1000
+
+// GetDependencies - Задачи, от которых зависит задача (GET /tasks/:id/dependencies)
+func GetDependencies(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	tx := txFromContext(c)
+	var task Task
+	if result := tx.First(&task, id); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+	tasks := []Task{}
+	tx.Where("id IN (?)", tx.Model(&TaskDependency{}).Select("depends_on_id").Where("task_id = ?", id)).
+		Order("id").Find(&tasks)
+	enrichTasks(c, tasks)
+	c.JSON(http.StatusOK, tasks)
+}
+
+// AddDependency - Добавить зависимость (POST /tasks/:id/dependencies)
+// Тело: {"dependsOnId": 5}. Повторное добавление той же зависимости ничего не меняет;
+// циклы здесь не запрещаются, о них сообщает GET /tasks/order.
+func AddDependency(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var requestBody struct {
+		DependsOnID uint `json:"dependsOnId" binding:"required"`
+	}
+	if err := bindJSON(c, &requestBody); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if requestBody.DependsOnID == id {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a task cannot depend on itself"})
+		return
+	}
+	tx := txFromContext(c)
+	var count int64
+	tx.Model(&Task{}).Where("id IN ?", []uint{id, requestBody.DependsOnID}).Count(&count)
+	if count != 2 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	dependency := TaskDependency{TaskID: id, DependsOnID: requestBody.DependsOnID}
+	if result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&dependency); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add dependency"})
+		return
+	}
+	c.JSON(http.StatusCreated, dependency)
+}
+
+// DeleteDependency - Удалить зависимость (DELETE /tasks/:id/dependencies/:dependsOnId)
+func DeleteDependency(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	dependsOnID, err := strconv.ParseUint(c.Param("dependsOnId"), 10, 64)
+	if err != nil || dependsOnID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task id", "code": "invalid_id"})
+		return
+	}
+	result := txFromContext(c).Where("task_id = ? AND depends_on_id = ?", id, dependsOnID).Delete(&TaskDependency{})
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dependency not found"})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// readyQueue - Очередь задач без невыполненных зависимостей; при равенстве первой идет
+// задача, стоящая раньше в исходном порядке (?sort=), чтобы результат был детерминированным
+type readyQueue []int
+
+func (q readyQueue) Len() int            { return len(q) }
+func (q readyQueue) Less(i, j int) bool  { return q[i] < q[j] }
+func (q readyQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *readyQueue) Push(x interface{}) { *q = append(*q, x.(int)) }
+func (q *readyQueue) Pop() interface{} {
+	old := *q
+	last := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return last
+}
+
+// topoOrder - Алгоритм Кана: порядок индексов задач, при котором каждая задача идет после
+// своих зависимостей. edges[i] - задачи, которые ждут i. Второе значение - индексы задач,
+// оставшихся в циклах (пусто, если циклов нет).
+func topoOrder(n int, edges [][]int) ([]int, []int) {
+	inDegree := make([]int, n)
+	for _, targets := range edges {
+		for _, target := range targets {
+			inDegree[target]++
+		}
+	}
+	ready := &readyQueue{}
+	for i := 0; i < n; i++ {
+		if inDegree[i] == 0 {
+			heap.Push(ready, i)
+		}
+	}
+
+	order := make([]int, 0, n)
+	for ready.Len() > 0 {
+		current := heap.Pop(ready).(int)
+		order = append(order, current)
+		for _, target := range edges[current] {
+			if inDegree[target]--; inDegree[target] == 0 {
+				heap.Push(ready, target)
+			}
+		}
+	}
+
+	var cyclic []int
+	if len(order) < n {
+		for i := 0; i < n; i++ {
+			if inDegree[i] > 0 {
+				cyclic = append(cyclic, i)
+			}
+		}
+	}
+	return order, cyclic
+}
+
+// GetCompletionOrder - Рекомендуемый порядок выполнения задач с учетом зависимостей (GET /tasks/order)
+// Набор задач задается теми же фильтрами, что и GET /tasks (по умолчанию - незавершенные),
+// учитываются только зависимости внутри набора. При цикле - 409 и задачи, входящие в цикл.
+func GetCompletionOrder(c *gin.Context) {
+	filter, err := parseTaskFilter(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.IsCompleted == nil && filter.Preset == "" && filter.Status == "" {
+		notCompleted := false
+		filter.IsCompleted = &notCompleted
+	}
+	query, order, ok := taskListQuery(c, filter)
+	if !ok {
+		return
+	}
+	var tasks []Task
+	if result := query.Order(order.expression()).Limit(maxOrderTasks + 1).Find(&tasks); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load tasks"})
+		return
+	}
+	if len(tasks) > maxOrderTasks {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Too many tasks to order, narrow the filter", "limit": maxOrderTasks})
+		return
+	}
+
+	index := make(map[uint]int, len(tasks))
+	ids := make([]uint, len(tasks))
+	for i, task := range tasks {
+		index[task.ID] = i
+		ids[i] = task.ID
+	}
+	var dependencies []TaskDependency
+	if len(ids) > 0 {
+		if result := txFromContext(c).Where("task_id IN ? AND depends_on_id IN ?", ids, ids).Find(&dependencies); result.Error != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load dependencies"})
+			return
+		}
+	}
+	edges := make([][]int, len(tasks))
+	for _, dependency := range dependencies {
+		from := index[dependency.DependsOnID]
+		edges[from] = append(edges[from], index[dependency.TaskID])
+	}
+
+	sorted, cyclic := topoOrder(len(tasks), edges)
+	if len(cyclic) > 0 {
+		cycleIDs := make([]uint, len(cyclic))
+		for i, position := range cyclic {
+			cycleIDs[i] = tasks[position].ID
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": "Task dependencies contain a cycle", "code": "dependency_cycle", "taskIds": cycleIDs})
+		return
+	}
+	ordered := make([]Task, len(sorted))
+	for i, position := range sorted {
+		ordered[i] = tasks[position]
+	}
+	enrichTasks(c, ordered)
+	c.JSON(http.StatusOK, gin.H{"data": ordered})
+}
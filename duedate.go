@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+)
+
+// --- Срок выполнения в свободной форме ---
+
+var (
+	// "in 3 days", "in 2 hours", "через 3 дня"
+	relativeInPattern = regexp.MustCompile(`^(?:in|через)\s+(\d+)\s*(minutes?|mins?|hours?|h|days?|d|weeks?|w|минут[уы]?|час(?:а|ов)?|дн(?:я|ей)|день|недел[юиь])$`)
+	// "tomorrow 9am", "today 18:30", "next week"
+	relativeDayPattern = regexp.MustCompile(`^(today|tomorrow|next week|сегодня|завтра|через неделю)(?:\s+(?:at\s+|в\s+)?(.+))?$`)
+	// "9am", "9:30pm", "18:30"
+	timeOfDayPattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+)
+
+// Время, которое подставляется в срок, заданный только датой (DEFAULT_DUE_TIME, например 09:00)
+var defaultDueHour, defaultDueMinute = 9, 0
+
+// parseDefaultDueTime - Разобрать значение DEFAULT_DUE_TIME
+func parseDefaultDueTime(raw string) (int, int) {
+	if strings.TrimSpace(raw) == "" {
+		return defaultDueHour, defaultDueMinute
+	}
+	hour, minute, err := parseTimeOfDay(strings.ToLower(raw))
+	if err != nil {
+		log.Printf("Invalid DEFAULT_DUE_TIME value %q, using %02d:%02d", raw, defaultDueHour, defaultDueMinute)
+		return defaultDueHour, defaultDueMinute
+	}
+	return hour, minute
+}
+
+// parseNaturalDate - Разобрать срок вида today, tomorrow 9am, next week, in N days/hours
+func parseNaturalDate(input string, now time.Time) (time.Time, error) {
+	input = strings.ToLower(strings.Join(strings.Fields(input), " "))
+
+	if m := relativeInPattern.FindStringSubmatch(input); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		unit := m[2]
+		switch {
+		case strings.HasPrefix(unit, "min"), strings.HasPrefix(unit, "минут"):
+			return now.Add(time.Duration(n) * time.Minute), nil
+		case strings.HasPrefix(unit, "h"), strings.HasPrefix(unit, "час"):
+			return now.Add(time.Duration(n) * time.Hour), nil
+		case strings.HasPrefix(unit, "d"), strings.HasPrefix(unit, "дн"), unit == "день":
+			return now.AddDate(0, 0, n), nil
+		default:
+			return now.AddDate(0, 0, 7*n), nil
+		}
+	}
+
+	m := relativeDayPattern.FindStringSubmatch(input)
+	if m == nil {
+		return time.Time{}, errors.New("unrecognized date expression")
+	}
+	day := now
+	switch m[1] {
+	case "tomorrow", "завтра":
+		day = now.AddDate(0, 0, 1)
+	case "next week", "через неделю":
+		day = now.AddDate(0, 0, 7)
+	}
+
+	hour, minute := defaultDueHour, defaultDueMinute
+	if m[2] != "" {
+		var err error
+		if hour, minute, err = parseTimeOfDay(m[2]); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, now.Location()), nil
+}
+
+// parseTimeOfDay - Разобрать время суток: 9am, 9:30pm, 18:30
+func parseTimeOfDay(input string) (int, int, error) {
+	m := timeOfDayPattern.FindStringSubmatch(strings.TrimSpace(input))
+	if m == nil {
+		return 0, 0, fmt.Errorf("unrecognized time of day %q", input)
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	if m[3] != "" && (hour < 1 || hour > 12) {
+		return 0, 0, fmt.Errorf("invalid time of day %q", input)
+	}
+	switch m[3] {
+	case "am":
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 12 {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, fmt.Errorf("invalid time of day %q", input)
+	}
+	return hour, minute, nil
+}
+
+// normalizeDueDate - Заменить dueDate в свободной форме на конкретную метку времени RFC3339
+// null и корректные RFC3339-значения остаются как есть. Дата без времени ("2024-06-01")
+// получает время DEFAULT_DUE_TIME в часовом поясе now.
+func normalizeDueDate(raw json.RawMessage, now time.Time) (json.RawMessage, error) {
+	var value *string
+	if err := json.Unmarshal(raw, &value); err != nil || value == nil {
+		return raw, nil // Не строка или null - пусть разбирается обычным образом
+	}
+	if _, err := time.Parse(time.RFC3339, *value); err == nil {
+		return raw, nil
+	}
+	if day, err := time.ParseInLocation(time.DateOnly, strings.TrimSpace(*value), now.Location()); err == nil {
+		// Через time.Date, а не day.Add: в день перевода часов в сутках 23 или 25 часов
+		return json.Marshal(time.Date(day.Year(), day.Month(), day.Day(), defaultDueHour, defaultDueMinute, 0, 0, day.Location()))
+	}
+	resolved, err := parseNaturalDate(*value, now)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dueDate %q: expected RFC3339 or an expression like \"tomorrow 9am\" or \"in 3 days\"", *value)
+	}
+	return json.Marshal(resolved)
+}
+
+// requestNow - Текущее время в часовом поясе запроса (?tz=), от него считаются относительные сроки
+func requestNow(c *gin.Context) (time.Time, error) {
+	loc, err := requestLocation(c)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Now().In(loc), nil
+}
+
+// bindTaskJSON - Аналог bindJSON для задачи с разбором dueDate в свободной форме
+func bindTaskJSON(c *gin.Context, task *Task) error {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return err
+	}
+	if raw, ok := fields["dueDate"]; ok {
+		now, err := requestNow(c)
+		if err != nil {
+			return err
+		}
+		normalized, err := normalizeDueDate(raw, now)
+		if err != nil {
+			return err
+		}
+		fields["dueDate"] = normalized
+		if body, err = json.Marshal(fields); err != nil {
+			return err
+		}
+	}
+	// Закрепление меняется только через POST /tasks/:id/pin, где действует лимит
+	if _, ok := fields["pinned"]; ok {
+		delete(fields, "pinned")
+		if body, err = json.Marshal(fields); err != nil {
+			return err
+		}
+	}
+
+	if err := decodeJSON(bytes.NewReader(body), task, strictFields(c)); err != nil {
+		return err
+	}
+	return binding.Validator.ValidateStruct(task)
+}
+
+// RescheduleOverdueTasks - Перенести все просроченные открытые задачи на новый срок (POST /tasks/reschedule-overdue)
+// Тело: {"to": "tomorrow"} - срок в том же свободном формате, что и dueDate ("tomorrow 9am", "in 3 days",
+// "2024-06-01", RFC3339), или длительность от текущего момента ("3d", "12h"). "Завтра" считается в поясе
+// ?tz= или из настроек пользователя. Все задачи получают один срок в одной транзакции; ответ - {"updated": N}.
+func RescheduleOverdueTasks(c *gin.Context) {
+	var requestBody struct {
+		To string `json:"to" binding:"required"`
+	}
+	if err := bindJSON(c, &requestBody); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	now, err := requestNow(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var target time.Time
+	if offset, err := parseRelativeDuration(strings.TrimSpace(requestBody.To)); err == nil {
+		target = now.Add(offset)
+	} else {
+		raw, _ := json.Marshal(requestBody.To)
+		normalized, err := normalizeDueDate(raw, now)
+		if err == nil {
+			err = json.Unmarshal(normalized, &target)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid to value %q: expected an expression like \"tomorrow\", a date or a duration like 3d", requestBody.To)})
+			return
+		}
+	}
+	// Иначе задачи остались бы просроченными
+	if !target.After(now) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "the new due date must be in the future"})
+		return
+	}
+
+	dueDate, _ := json.Marshal(target)
+	updateTasksWhere(c, map[string]json.RawMessage{"dueDate": dueDate}, func(tx *gorm.DB) *gorm.DB {
+		overdue := scopeToUser(c, taskPresets["overdue"](tx.Model(&Task{}).Select("id")))
+		return tx.Model(&Task{}).Where("id IN (?)", overdue)
+	})
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestNormalizeDueDateDefaultTimeAcrossDST - Дата без времени получает DEFAULT_DUE_TIME по местным часам
+// и в дни перевода часов
+func TestNormalizeDueDateDefaultTimeAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data is not available: %v", err)
+	}
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, loc)
+	for _, date := range []string{"2024-03-30", "2024-03-31", "2024-10-27", "2024-06-01"} {
+		raw, err := normalizeDueDate(json.RawMessage(`"`+date+`"`), now)
+		if err != nil {
+			t.Fatalf("normalizeDueDate(%s): %v", date, err)
+		}
+		var due time.Time
+		if err := json.Unmarshal(raw, &due); err != nil {
+			t.Fatalf("decode %s: %v", raw, err)
+		}
+		local := due.In(loc)
+		if got := local.Format(time.DateOnly + " 15:04"); got != date+" 09:00" {
+			t.Errorf("due date for %s = %s (%s), want %s 09:00", date, got, raw, date)
+		}
+	}
+}
+
+// TestRescheduleOverdueTasks - Новый срок получают только просроченные открытые задачи пользователя,
+// а срок, который не разобрать или который уже прошел, отклоняется
+func TestRescheduleOverdueTasks(t *testing.T) {
+	setupTestDB(t)
+	yesterday := time.Now().Add(-24 * time.Hour)
+	nextMonth := time.Now().AddDate(0, 1, 0)
+	overdue := createTestTask(t, Task{Title: "Просрочена", DueDate: &yesterday, CreatedBy: stringPtr("alice")})
+	done := createTestTask(t, Task{Title: "Сделана", DueDate: &yesterday, Status: StatusDone, IsCompleted: true, CreatedBy: stringPtr("alice")})
+	future := createTestTask(t, Task{Title: "Не горит", DueDate: &nextMonth, CreatedBy: stringPtr("alice")})
+	foreign := createTestTask(t, Task{Title: "Чужая", DueDate: &yesterday, CreatedBy: stringPtr("bob")})
+
+	for _, body := range []string{`{"to": "someday"}`, `{"to": "2020-01-01"}`, `{}`} {
+		w := performRequest(http.MethodPost, "/tasks/reschedule-overdue", body, "alice")
+		expectStatus(t, w, http.StatusBadRequest)
+	}
+
+	before := time.Now()
+	w := performRequest(http.MethodPost, "/tasks/reschedule-overdue", `{"to": "3d"}`, "alice")
+	expectStatus(t, w, http.StatusOK)
+	if !strings.Contains(w.Body.String(), `"updated":1`) {
+		t.Errorf("response %s, want updated 1", w.Body.String())
+	}
+
+	var got Task
+	if err := db.First(&got, overdue.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if want := before.Add(72 * time.Hour); got.DueDate == nil || got.DueDate.Before(want.Add(-time.Second)) || got.DueDate.After(want.Add(time.Minute)) {
+		t.Errorf("rescheduled dueDate = %v, want about %v", got.DueDate, want)
+	}
+	for _, task := range []Task{done, future, foreign} {
+		if err := db.First(&got, task.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		// В БД время хранится с точностью до микросекунд
+		if got.DueDate == nil || got.DueDate.Sub(*task.DueDate).Abs() > time.Millisecond {
+			t.Errorf("task %q dueDate changed to %v", task.Title, got.DueDate)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// --- Копирование задач ---
+
+// duplicateOptions - Что делать со сроком и подзадачами при копировании
+type duplicateOptions struct {
+	ClearDueDate bool
+	DueShift     time.Duration
+	WithSubtasks bool
+	CreatedBy    *string // Автор копий - пользователь, сделавший копию
+}
+
+// copyTask - Новая незавершенная задача с полями исходной (без id, внешнего id и связей)
+func copyTask(source Task, parentID *uint, opts duplicateOptions) Task {
+	task := Task{
+		Title:       source.Title,
+		Description: source.Description,
+		Priority:    source.Priority,
+		Tags:        source.Tags,
+		Flag:        source.Flag,
+		ParentID:    parentID,
+		Position:    source.Position,
+		CreatedBy:   opts.CreatedBy,
+	}
+	if source.DueDate != nil && !opts.ClearDueDate {
+		due := source.DueDate.Add(opts.DueShift)
+		task.DueDate = &due
+	}
+	return task
+}
+
+// duplicateSubtasks - Рекурсивно скопировать подзадачи source под новую задачу copyID
+func duplicateSubtasks(tx *gorm.DB, sourceID, copyID uint, opts duplicateOptions) error {
+	var children []Task
+	if err := tx.Where("parent_id = ?", sourceID).Order("position, id").Find(&children).Error; err != nil {
+		return err
+	}
+	for _, child := range children {
+		childCopy := copyTask(child, &copyID, opts)
+		if err := tx.Create(&childCopy).Error; err != nil {
+			return err
+		}
+		if err := duplicateSubtasks(tx, child.ID, childCopy.ID, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DuplicateTask - Создать копию задачи (POST /tasks/:id/duplicate)
+// Название получает префикс "Copy of", выполнение сбрасывается, копия встает в конец списка
+// того же родителя. ?clearDueDate=true убирает срок, ?shiftDueDate=+7d сдвигает его,
+// ?withSubtasks=true копирует и все подзадачи.
+func DuplicateTask(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var source Task
+	if result := txFromContext(c).First(&source, id); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	opts := duplicateOptions{
+		ClearDueDate: c.Query("clearDueDate") == "true",
+		WithSubtasks: c.Query("withSubtasks") == "true",
+		CreatedBy:    currentUserID(c),
+	}
+	if raw := c.Query("shiftDueDate"); raw != "" {
+		if opts.ClearDueDate {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "clearDueDate cannot be combined with shiftDueDate"})
+			return
+		}
+		shift, err := parseRelativeDuration(strings.TrimPrefix(raw, "+"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid shiftDueDate value, expected an offset like +7d or -12h"})
+			return
+		}
+		opts.DueShift = shift
+	}
+
+	task := copyTask(source, source.ParentID, opts)
+	task.Title = "Copy of " + source.Title
+	if err := validateTask(txFromContext(c), &task); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	adding := int64(1)
+	if opts.WithSubtasks {
+		var descendants int64
+		txFromContext(c).Raw(`WITH RECURSIVE tree AS (
+				SELECT id FROM tasks WHERE parent_id = ? AND deleted_at IS NULL
+				UNION ALL
+				SELECT t.id FROM tasks t JOIN tree ON t.parent_id = tree.id WHERE t.deleted_at IS NULL
+			) SELECT COUNT(*) FROM tree`, source.ID).Scan(&descendants)
+		adding += descendants
+	}
+	if !checkTaskLimit(c, int(adding)) {
+		return
+	}
+
+	err := txFromContext(c).Transaction(func(tx *gorm.DB) error {
+		task.Position = nextPosition(tx, task.ParentID)
+		if err := tx.Create(&task).Error; err != nil {
+			return err
+		}
+		if opts.WithSubtasks {
+			return duplicateSubtasks(tx, source.ID, task.ID, opts)
+		}
+		return nil
+	})
+	if err != nil {
+		if isDuplicateTitle(err) {
+			respondDuplicateTitle(c)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to duplicate task"})
+		return
+	}
+	enrichTask(c, &task)
+	c.Header("Location", resourceLocation("tasks", task.ID))
+	c.JSON(http.StatusCreated, task)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"log"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// --- Расширения ответа (?expand=) ---
+
+// taskExpansions - Поддерживаемые вычисляемые расширения: имя -> пакетная загрузка для списка задач
+// Каждое расширение - один запрос на весь список. Неизвестные имена игнорируются.
+var taskExpansions = map[string]func(tx *gorm.DB, tasks []Task, ids []uint) error{
+	"subtasks.count":     expandSubtaskCount,
+	"timeEntries.count":  expandTimeEntryCount,
+	"timeEntries.latest": expandLatestTimeEntry,
+}
+
+// setExpansion - Записать значение расширения в задачу
+func (t *Task) setExpansion(name string, value interface{}) {
+	if t.Expanded == nil {
+		t.Expanded = make(map[string]interface{})
+	}
+	t.Expanded[name] = value
+}
+
+// attachExpansions - Заполнить расширения, запрошенные через ?expand=a,b
+func attachExpansions(c *gin.Context, tasks []Task) {
+	raw := c.Query("expand")
+	if raw == "" || len(tasks) == 0 {
+		return
+	}
+	ids := make([]uint, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		expand, ok := taskExpansions[name]
+		if !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+		if err := expand(txFromContext(c), tasks, ids); err != nil {
+			log.Printf("Failed to expand %s: %v", name, err)
+		}
+	}
+}
+
+// expandSubtaskCount - Число подзадач
+func expandSubtaskCount(tx *gorm.DB, tasks []Task, ids []uint) error {
+	var rows []struct {
+		ParentID uint
+		Count    int64
+	}
+	err := tx.Model(&Task{}).
+		Select("parent_id, COUNT(*) AS count").
+		Where("parent_id IN ?", ids).
+		Group("parent_id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.ParentID] = row.Count
+	}
+	for i := range tasks {
+		tasks[i].setExpansion("subtasks.count", counts[tasks[i].ID])
+	}
+	return nil
+}
+
+// expandTimeEntryCount - Число записей учета времени
+func expandTimeEntryCount(tx *gorm.DB, tasks []Task, ids []uint) error {
+	var rows []struct {
+		TaskID uint
+		Count  int64
+	}
+	err := tx.Model(&TimeEntry{}).
+		Select("task_id, COUNT(*) AS count").
+		Where("task_id IN ?", ids).
+		Group("task_id").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.TaskID] = row.Count
+	}
+	for i := range tasks {
+		tasks[i].setExpansion("timeEntries.count", counts[tasks[i].ID])
+	}
+	return nil
+}
+
+// expandLatestTimeEntry - Последняя запись учета времени (null, если записей нет)
+func expandLatestTimeEntry(tx *gorm.DB, tasks []Task, ids []uint) error {
+	var entries []TimeEntry
+	err := tx.Raw(`SELECT DISTINCT ON (task_id) * FROM time_entries
+		WHERE task_id IN ? ORDER BY task_id, started_at DESC, id DESC`, ids).
+		Scan(&entries).Error
+	if err != nil {
+		return err
+	}
+	latest := make(map[uint]*TimeEntry, len(entries))
+	for i := range entries {
+		latest[entries[i].TaskID] = &entries[i]
+	}
+	for i := range tasks {
+		tasks[i].setExpansion("timeEntries.latest", latest[tasks[i].ID])
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// --- Экспорт задач ---
+
+// markdownEscaper - Экранирование символов разметки Markdown в пользовательском тексте
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "{", `\{`, "}", `\}`,
+	"[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`, "(", `\(`, ")", `\)`,
+	"#", `\#`, "+", `\+`, "-", `\-`, "!", `\!`, "|", `\|`, "~", `\~`,
+)
+
+// escapeMarkdown - Экранировать текст для вставки в Markdown
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// escapeMarkdownLine - Экранировать текст для заголовка или ячейки таблицы (без переводов строк)
+func escapeMarkdownLine(s string) string {
+	return escapeMarkdown(strings.Join(strings.Fields(s), " "))
+}
+
+// renderTaskMarkdown - Задача в Markdown: заголовок, таблица метаданных, описание и чек-лист подзадач
+func renderTaskMarkdown(task Task, subtasks []Task) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", escapeMarkdownLine(task.Title))
+
+	b.WriteString("| Field | Value |\n|---|---|\n")
+	row := func(field, value string) {
+		if value == "" {
+			value = "—"
+		}
+		fmt.Fprintf(&b, "| %s | %s |\n", field, value)
+	}
+	row("Status", task.Status)
+	row("Priority", escapeMarkdownLine(task.PriorityLabel))
+	if task.DueDate != nil {
+		row("Due date", task.DueDate.Format(time.RFC3339))
+	} else {
+		row("Due date", "")
+	}
+	row("Tags", escapeMarkdownLine(strings.Join(splitTags(task.Tags), ", ")))
+	if task.Progress != nil {
+		row("Progress", fmt.Sprintf("%.0f%%", *task.Progress*100))
+	}
+	row("Created", task.CreatedAt.Format(time.RFC3339))
+	row("Updated", task.UpdatedAt.Format(time.RFC3339))
+
+	if description := strings.TrimSpace(task.Description); description != "" {
+		fmt.Fprintf(&b, "\n%s\n", escapeMarkdown(description))
+	}
+
+	if len(subtasks) > 0 {
+		b.WriteString("\n## Subtasks\n\n")
+		for _, subtask := range subtasks {
+			mark := " "
+			if subtask.IsCompleted {
+				mark = "x"
+			}
+			fmt.Fprintf(&b, "- [%s] %s\n", mark, escapeMarkdownLine(subtask.Title))
+		}
+	}
+	return b.String()
+}
+
+// ExportTask - Экспорт одной задачи (GET /tasks/:id/export?format=md)
+// Пока поддерживается только Markdown; ответ отдается как файл task-<id>.md.
+func ExportTask(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	if format := c.DefaultQuery("format", "md"); format != "md" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported export format %q, expected md", format)})
+		return
+	}
+	tx := txFromContext(c)
+	var task Task
+	if result := scopeToUser(c, tx).First(&task, id); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+	var subtasks []Task
+	if result := tx.Where("parent_id = ?", task.ID).Order("position, id").Find(&subtasks); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load subtasks"})
+		return
+	}
+	enrichTask(c, &task)
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="task-%d.md"`, task.ID))
+	c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(renderTaskMarkdown(task, subtasks)))
+}
+
+// scopeToUser - Ограничить выборку задачами текущего пользователя (автор или исполнитель)
+// Без заголовка пользователя (установка без аутентифицирующего прокси) ограничения нет.
+func scopeToUser(c *gin.Context, query *gorm.DB) *gorm.DB {
+	userID := currentUserID(c)
+	if userID == nil {
+		return query
+	}
+	return query.Where("created_by = ? OR assignee_id = ?", *userID, *userID)
+}
+
+// scopeToOwner - Ограничить выборку задачами, созданными текущим пользователем
+// Для удаления: задачи, которые другие пользователи только назначили ему, не затрагиваются.
+func scopeToOwner(c *gin.Context, query *gorm.DB) *gorm.DB {
+	userID := currentUserID(c)
+	if userID == nil {
+		return query
+	}
+	return query.Where("created_by = ?", *userID)
+}
+
+// taskExporters - Форматы массового экспорта: MIME-тип, расширение файла и функция рендеринга
+var taskExporters = map[string]struct {
+	contentType string
+	render      func(tasks []Task) ([]byte, error)
+}{
+	"csv":  {"text/csv; charset=utf-8", renderTasksCSV},
+	"json": {"application/json; charset=utf-8", renderTasksJSON},
+	"ics":  {"text/calendar; charset=utf-8", renderTasksICS},
+}
+
+// ExportTasks - Массовый экспорт задач (GET /tasks/export?format=csv|json|ics)
+// Принимает те же фильтры, что и GET /tasks, и ограничен задачами текущего пользователя (scopeToUser).
+// Запрос без заголовка пользователя выгружает все задачи: так работает установка без
+// аутентифицирующего прокси, где пользователей нет; за прокси заголовок есть у каждого запроса.
+func ExportTasks(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	exporter, ok := taskExporters[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported export format %q, expected csv, json or ics", format)})
+		return
+	}
+	filter, err := parseTaskFilter(c.Request.URL.Query())
+	if err == nil {
+		err = resolveAssignee(c, &filter)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var tasks []Task
+	query := applyTaskFilter(scopeToUser(c, txFromContext(c).Model(&Task{})), filter)
+	if result := query.Order("id").Find(&tasks); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load tasks"})
+		return
+	}
+	body, err := exporter.render(tasks)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export tasks"})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="tasks.%s"`, format))
+	c.Data(http.StatusOK, exporter.contentType, body)
+}
+
+// optionalTime - Время в RFC3339 или пустая строка
+func optionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// renderTasksCSV - Задачи в CSV с заголовком
+func renderTasksCSV(tasks []Task) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"id", "title", "description", "priority", "status", "isCompleted", "dueDate",
+		"tags", "parentId", "assigneeId", "createdBy", "createdAt", "updatedAt"})
+	for _, t := range tasks {
+		parentID, assigneeID, createdBy := "", "", ""
+		if t.ParentID != nil {
+			parentID = strconv.FormatUint(uint64(*t.ParentID), 10)
+		}
+		if t.AssigneeID != nil {
+			assigneeID = *t.AssigneeID
+		}
+		if t.CreatedBy != nil {
+			createdBy = *t.CreatedBy
+		}
+		w.Write([]string{
+			strconv.FormatUint(uint64(t.ID), 10), t.Title, t.Description, t.Priority, t.Status,
+			strconv.FormatBool(t.IsCompleted), optionalTime(t.DueDate), t.Tags, parentID, assigneeID,
+			createdBy, t.CreatedAt.Format(time.RFC3339), t.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// renderTasksJSON - Задачи JSON-массивом
+func renderTasksJSON(tasks []Task) ([]byte, error) {
+	if tasks == nil {
+		tasks = []Task{}
+	}
+	return json.Marshal(tasks)
+}
+
+// icsEscaper - Экранирование текста по RFC 5545
+var icsEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+// icsLine - Строка iCalendar с переносом длинных строк (не длиннее 75 октетов)
+func icsLine(b *strings.Builder, line string) {
+	for len(line) > 75 {
+		cut := 75
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+	}
+	b.WriteString(line + "\r\n")
+}
+
+// icsStatuses - Статус задачи в терминах VTODO
+var icsStatuses = map[string]string{
+	StatusTodo: "NEEDS-ACTION", StatusInProgress: "IN-PROCESS", StatusBlocked: "NEEDS-ACTION", StatusDone: "COMPLETED",
+}
+
+// icsPriorities - Приоритет в шкале iCalendar (1 - наивысший)
+var icsPriorities = map[string]string{PriorityHigh: "1", PriorityMedium: "5", PriorityLow: "9"}
+
+// renderTasksICS - Задачи как VTODO в календаре iCalendar
+func renderTasksICS(tasks []Task) ([]byte, error) {
+	const stamp = "20060102T150405Z"
+	var b strings.Builder
+	icsLine(&b, "BEGIN:VCALENDAR")
+	icsLine(&b, "VERSION:2.0")
+	icsLine(&b, "PRODID:-//my-task-app//tasks//EN")
+	for _, t := range tasks {
+		icsLine(&b, "BEGIN:VTODO")
+		icsLine(&b, fmt.Sprintf("UID:task-%d@my-task-app", t.ID))
+		icsLine(&b, "DTSTAMP:"+t.UpdatedAt.UTC().Format(stamp))
+		icsLine(&b, "SUMMARY:"+icsEscaper.Replace(t.Title))
+		if t.Description != "" {
+			icsLine(&b, "DESCRIPTION:"+icsEscaper.Replace(t.Description))
+		}
+		if t.DueDate != nil {
+			icsLine(&b, "DUE:"+t.DueDate.UTC().Format(stamp))
+		}
+		if status, ok := icsStatuses[t.Status]; ok {
+			icsLine(&b, "STATUS:"+status)
+		}
+		if priority, ok := icsPriorities[t.Priority]; ok {
+			icsLine(&b, "PRIORITY:"+priority)
+		}
+		if tags := splitTags(t.Tags); len(tags) > 0 {
+			for i := range tags {
+				tags[i] = icsEscaper.Replace(tags[i])
+			}
+			icsLine(&b, "CATEGORIES:"+strings.Join(tags, ","))
+		}
+		icsLine(&b, "END:VTODO")
+	}
+	icsLine(&b, "END:VCALENDAR")
+	return []byte(b.String()), nil
+}
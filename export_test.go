@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestExportTasksScopedToUser - Пользователь выгружает только свои задачи (созданные или назначенные ему)
+// в любом формате; без заголовка пользователя выгружается все
+func TestExportTasksScopedToUser(t *testing.T) {
+	setupTestDB(t)
+	createTestTask(t, Task{Title: "Alice own", CreatedBy: stringPtr("alice")})
+	createTestTask(t, Task{Title: "Assigned to alice", CreatedBy: stringPtr("bob"), AssigneeID: stringPtr("alice")})
+	createTestTask(t, Task{Title: "Bob secret", CreatedBy: stringPtr("bob")})
+
+	for _, format := range []string{"csv", "json", "ics"} {
+		w := performRequest(http.MethodGet, "/tasks/export?format="+format, "", "alice")
+		expectStatus(t, w, http.StatusOK)
+		body := w.Body.String()
+		if strings.Contains(body, "Bob secret") {
+			t.Errorf("%s export for alice contains bob's task:\n%s", format, body)
+		}
+		if !strings.Contains(body, "Alice own") || !strings.Contains(body, "Assigned to alice") {
+			t.Errorf("%s export for alice misses her tasks:\n%s", format, body)
+		}
+	}
+
+	w := performRequest(http.MethodGet, "/tasks/export?format=json", "", "")
+	expectStatus(t, w, http.StatusOK)
+	var all []Task
+	if err := json.Unmarshal(w.Body.Bytes(), &all); err != nil {
+		t.Fatalf("decode export: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("export without a user header returned %d tasks, want all 3", len(all))
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- Флаги функций ---
+
+// Версия сборки, задается при сборке: go build -ldflags "-X main.version=1.2.3"
+var version = "dev"
+
+// features - Флаги необязательных функций и их значения по умолчанию
+// Переопределяются переменными окружения FEATURE_<ИМЯ>, например FEATURE_AI=false.
+var features = map[string]bool{
+	"ai":         true,
+	"search":     true,
+	"board":      true,
+	"export":     true,
+	"checklists": true,
+	"debug":      false, // Отладочные конечные точки (GET /tasks/explain), не для продакшена
+}
+
+// initFeatures - Прочитать флаги функций из окружения
+func initFeatures() {
+	for name, def := range features {
+		features[name] = getEnvBool("FEATURE_"+strings.ToUpper(name), def)
+	}
+}
+
+// featureEnabled - Включена ли функция (неизвестные имена считаются выключенными)
+func featureEnabled(name string) bool {
+	return features[name]
+}
+
+// enabledFeatures - Имена включенных функций по алфавиту
+func enabledFeatures() []string {
+	names := []string{}
+	for name, enabled := range features {
+		if enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// requireFeature - Отвечает 404, если функция выключена
+func requireFeature(name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !featureEnabled(name) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Feature is disabled", "code": "feature_disabled"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// GetVersion - Версия сборки и включенные функции
+func GetVersion(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"version": version, "features": enabledFeatures()})
+}
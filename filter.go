@@ -0,0 +1,405 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// --- Фильтр задач ---
+
+// TaskFilter - Критерии отбора задач. Используется и обработчиком списка,
+// и ИИ-агентом, который превращает запрос пользователя в такой фильтр.
+type TaskFilter struct {
+	Preset      string     `json:"preset,omitempty"` // Готовая выборка из taskPresets (?filter=)
+	Search      string     `json:"search,omitempty"`
+	Fuzzy       bool       `json:"fuzzy,omitempty"`
+	Priority    string     `json:"priority,omitempty"`
+	Flag        string     `json:"flag,omitempty"` // Флажок или "none" - задачи без флажка
+	IsCompleted *bool      `json:"isCompleted,omitempty"`
+	Status      string     `json:"status,omitempty"`
+	Tag         string     `json:"tag,omitempty"`
+	Assignee    string     `json:"assignee,omitempty"` // ID исполнителя, "me" или "none"
+	DueBefore   *time.Time `json:"dueBefore,omitempty"`
+	DueAfter    *time.Time `json:"dueAfter,omitempty"`
+	NeedsReview *bool      `json:"needsReview,omitempty"` // Незавершенные задачи без ключевых полей (NEEDS_REVIEW_FIELDS)
+
+	// Начало окна ?createdWithin= и ?completedWithin= (момент разбора минус длительность)
+	CreatedSince   *time.Time `json:"createdSince,omitempty"`
+	CompletedSince *time.Time `json:"completedSince,omitempty"`
+
+	// OR-группы: условия внутри группы объединяются через OR, группы между собой и
+	// с остальными полями - через AND. Из строки запроса: ?or=priority:высокий,priority:средний
+	OrGroups [][]TaskFilter `json:"orGroups,omitempty"`
+}
+
+// whereOverdue - Открытые задачи не из архива со сроком раньше before
+// Условие подобрано под частичный индекс idx_tasks_overdue: NOT is_completed без параметра,
+// а строгое сравнение due_date < ? доказывает планировщику due_date IS NOT NULL.
+func whereOverdue(q *gorm.DB, before time.Time) *gorm.DB {
+	return q.Where("archived_at IS NULL AND NOT is_completed AND due_date < ?", before)
+}
+
+// whereDueBetween - Открытые задачи не из архива со сроком в [from, to)
+func whereDueBetween(q *gorm.DB, from, to time.Time) *gorm.DB {
+	return q.Where("archived_at IS NULL AND NOT is_completed AND due_date >= ? AND due_date < ?", from, to)
+}
+
+// taskPresets - Готовые выборки для ?filter=: имя -> составное условие
+var taskPresets = map[string]func(*gorm.DB) *gorm.DB{
+	"active": func(q *gorm.DB) *gorm.DB {
+		return q.Where("archived_at IS NULL AND NOT is_completed")
+	},
+	"archived": func(q *gorm.DB) *gorm.DB {
+		return q.Where("archived_at IS NOT NULL")
+	},
+	"completed": func(q *gorm.DB) *gorm.DB {
+		return q.Where("archived_at IS NULL AND is_completed")
+	},
+	"overdue": func(q *gorm.DB) *gorm.DB {
+		return whereOverdue(q, time.Now())
+	},
+	// Неразобранные: открытые задачи верхнего уровня без срока и приоритета
+	"inbox": func(q *gorm.DB) *gorm.DB {
+		return q.Where("archived_at IS NULL AND NOT is_completed AND parent_id IS NULL AND due_date IS NULL AND priority = ''")
+	},
+}
+
+// presetNames - Имена готовых выборок по алфавиту (для сообщений об ошибках)
+func presetNames() []string {
+	names := make([]string, 0, len(taskPresets))
+	for name := range taskPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseTaskFilter - Разобрать параметры строки запроса в TaskFilter
+func parseTaskFilter(values url.Values) (TaskFilter, error) {
+	filter := TaskFilter{
+		Search: strings.TrimSpace(values.Get("search")),
+		Fuzzy:  values.Get("fuzzy") == "true",
+		Tag:    strings.TrimSpace(values.Get("tag")),
+	}
+
+	if raw := strings.ToLower(strings.TrimSpace(values.Get("filter"))); raw != "" {
+		if _, ok := taskPresets[raw]; !ok {
+			return filter, fmt.Errorf("unknown filter %q, expected one of %s", raw, strings.Join(presetNames(), ", "))
+		}
+		filter.Preset = raw
+	}
+
+	if err := validTagFilter(filter.Tag); err != nil {
+		return filter, err
+	}
+
+	priority, err := normalizePriority(values.Get("priority"))
+	if err != nil {
+		return filter, err
+	}
+	filter.Priority = priority
+
+	if raw := strings.TrimSpace(values.Get("flag")); raw != "" {
+		if strings.EqualFold(raw, "none") {
+			filter.Flag = "none"
+		} else if filter.Flag, err = normalizeFlag(raw); err != nil {
+			return filter, err
+		}
+	}
+
+	if raw := strings.ToLower(strings.TrimSpace(values.Get("status"))); raw != "" {
+		if err := validStatus(raw); err != nil {
+			return filter, err
+		}
+		filter.Status = raw
+	}
+	if raw := values.Get("completed"); raw != "" {
+		completed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, fmt.Errorf("invalid completed value %q", raw)
+		}
+		filter.IsCompleted = &completed
+	}
+	filter.Assignee = strings.TrimSpace(values.Get("assignee"))
+	if raw := values.Get("needsReview"); raw != "" {
+		needsReview, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, fmt.Errorf("invalid needsReview value %q", raw)
+		}
+		filter.NeedsReview = &needsReview
+	}
+	if raw := values.Get("dueBefore"); raw != "" {
+		t, err := parseFilterTime(raw)
+		if err != nil {
+			return filter, fmt.Errorf("invalid dueBefore value %q, expected RFC3339 or a relative offset like +7d", raw)
+		}
+		filter.DueBefore = &t
+	}
+	if raw := values.Get("dueAfter"); raw != "" {
+		t, err := parseFilterTime(raw)
+		if err != nil {
+			return filter, fmt.Errorf("invalid dueAfter value %q, expected RFC3339 or a relative offset like +7d", raw)
+		}
+		filter.DueAfter = &t
+	}
+	if raw := values.Get("createdWithin"); raw != "" {
+		since, err := parseWindowStart(raw)
+		if err != nil {
+			return filter, fmt.Errorf("invalid createdWithin value %q, expected a positive duration like 7d or 12h", raw)
+		}
+		filter.CreatedSince = &since
+	}
+	if raw := values.Get("completedWithin"); raw != "" {
+		since, err := parseWindowStart(raw)
+		if err != nil {
+			return filter, fmt.Errorf("invalid completedWithin value %q, expected a positive duration like 7d or 12h", raw)
+		}
+		filter.CompletedSince = &since
+	}
+	for _, raw := range values["or"] {
+		group, err := parseOrGroup(raw)
+		if err != nil {
+			return filter, err
+		}
+		filter.OrGroups = append(filter.OrGroups, group)
+	}
+	return filter, nil
+}
+
+// parseOrGroup - Разобрать группу вида "priority:высокий,completed:false"
+// Каждое условие разбирается тем же parseTaskFilter, что и обычные параметры.
+func parseOrGroup(raw string) ([]TaskFilter, error) {
+	var group []TaskFilter
+	for _, part := range strings.Split(raw, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid or condition %q, expected field:value", part)
+		}
+		if !taskFilterParams[key] || key == "fuzzy" {
+			return nil, fmt.Errorf("unsupported field %q in or condition", key)
+		}
+		condition, err := parseTaskFilter(url.Values{key: {value}})
+		if err != nil {
+			return nil, err
+		}
+		group = append(group, condition)
+	}
+	return group, nil
+}
+
+// taskFilterParams - Параметры строки запроса, которые понимает parseTaskFilter
+var taskFilterParams = map[string]bool{
+	"filter": true, "search": true, "fuzzy": true, "priority": true, "flag": true, "completed": true, "status": true,
+	"assignee": true, "needsReview": true, "tag": true, "dueBefore": true, "dueAfter": true, "or": true,
+	"createdWithin": true, "completedWithin": true,
+}
+
+// parseFilterTime - Разобрать время в фильтре: RFC3339 или смещение от текущего момента
+// вида +7d, -12h, 30m (знак можно опустить; "+" в URL без кодирования превращается в пробел).
+func parseFilterTime(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	offset, err := parseRelativeDuration(strings.TrimPrefix(raw, "+"))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Now().Add(offset), nil
+}
+
+// parseRelativeDuration - time.ParseDuration с поддержкой дней ("7d", "-1d")
+func parseRelativeDuration(raw string) (time.Duration, error) {
+	if strings.HasSuffix(raw, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(raw, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", raw)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(raw)
+}
+
+// parseWindowStart - Начало окна "за последние N": 30d -> сейчас минус 30 дней
+func parseWindowStart(raw string) (time.Time, error) {
+	window, err := parseRelativeDuration(strings.TrimSpace(raw))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if window <= 0 {
+		return time.Time{}, fmt.Errorf("window must be positive")
+	}
+	return time.Now().Add(-window), nil
+}
+
+// validTagFilter - Тег для ?tag=: запятая разделяет теги в строке tags, поэтому внутри
+// одного тега ее быть не может (остальные символы, включая "%", сравниваются буквально)
+func validTagFilter(tag string) error {
+	if strings.Contains(tag, ",") {
+		return fmt.Errorf("invalid tag %q: a tag cannot contain a comma", tag)
+	}
+	return nil
+}
+
+// likeEscaper - Экранирование спецсимволов шаблона LIKE (в Postgres символ экранирования по умолчанию - обратный слеш)
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike - Экранировать пользовательский ввод для подстановки в шаблон LIKE/ILIKE,
+// чтобы "%" и "_" искались буквально, а не работали как подстановочные знаки
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
+
+// applyTaskFilter - Добавить условия фильтра к запросу
+// Все значения передаются только через плейсхолдеры, без склейки строк SQL.
+func applyTaskFilter(query *gorm.DB, filter TaskFilter) *gorm.DB {
+	if filter.Preset != "" {
+		query = taskPresets[filter.Preset](query)
+	}
+	if filter.Search != "" {
+		if filter.Fuzzy {
+			// Порядок по похожести задает findTaskPage
+			query = query.Where("similarity(title, ?) > ?", filter.Search, fuzzyThreshold)
+		} else {
+			pattern := "%" + escapeLike(filter.Search) + "%"
+			query = query.Where("title ILIKE ? OR description ILIKE ?", pattern, pattern)
+		}
+	}
+	if filter.Priority != "" {
+		query = query.Where("priority = ?", filter.Priority)
+	}
+	switch filter.Flag {
+	case "":
+	case "none":
+		query = query.Where("flag = ''")
+	default:
+		query = query.Where("flag = ?", filter.Flag)
+	}
+	if filter.IsCompleted != nil {
+		query = query.Where("is_completed = ?", *filter.IsCompleted)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	switch filter.Assignee {
+	case "":
+	case "none":
+		query = query.Where("assignee_id IS NULL")
+	default:
+		query = query.Where("assignee_id = ?", filter.Assignee)
+	}
+	// Тег сравнивается с каждым тегом задачи целиком, как их разбивает splitTags (без учета регистра):
+	// подстрока "срочно" не должна находить "несрочно"
+	if filter.Tag != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM unnest(string_to_array(tags, ',')) AS tag WHERE lower(btrim(tag)) = lower(?))", filter.Tag)
+	}
+	if filter.DueBefore != nil {
+		query = query.Where("due_date < ?", *filter.DueBefore)
+	}
+	if filter.DueAfter != nil {
+		query = query.Where("due_date > ?", *filter.DueAfter)
+	}
+	if filter.NeedsReview != nil {
+		query = applyNeedsReview(query, *filter.NeedsReview)
+	}
+	if filter.CreatedSince != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedSince)
+	}
+	if filter.CompletedSince != nil {
+		query = query.Where("is_completed AND completed_at >= ?", *filter.CompletedSince)
+	}
+	for _, group := range filter.OrGroups {
+		if len(group) == 0 {
+			continue
+		}
+		// Каждая альтернатива строится на чистой сессии, а затем группа
+		// добавляется как одно условие в скобках: (a OR b OR ...)
+		var conditions *gorm.DB
+		for _, alternative := range group {
+			condition := applyTaskFilter(db.Session(&gorm.Session{NewDB: true}), alternative)
+			if conditions == nil {
+				conditions = db.Session(&gorm.Session{NewDB: true}).Where(condition)
+			} else {
+				conditions = conditions.Or(condition)
+			}
+		}
+		query = query.Where(conditions)
+	}
+	return query
+}
+
+// resolveAssignee - Заменить ?assignee=me на ID текущего пользователя (и в OR-группах)
+// Без аутентификации "me" не имеет смысла - это ошибка запроса.
+func resolveAssignee(c *gin.Context, filter *TaskFilter) error {
+	if filter.Assignee == "me" {
+		userID := currentUserID(c)
+		if userID == nil {
+			return fmt.Errorf("assignee=me requires an authenticated user")
+		}
+		filter.Assignee = *userID
+	}
+	for _, group := range filter.OrGroups {
+		for i := range group {
+			if err := resolveAssignee(c, &group[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isEmpty - Фильтр не содержит ни одного условия
+func (f TaskFilter) isEmpty() bool {
+	return f.Preset == "" && f.Search == "" && f.Priority == "" && f.Flag == "" && f.IsCompleted == nil && f.Status == "" && f.Assignee == "" &&
+		f.Tag == "" && f.DueBefore == nil && f.DueAfter == nil && f.NeedsReview == nil &&
+		f.CreatedSince == nil && f.CompletedSince == nil && len(f.OrGroups) == 0
+}
+
+// sortColumns - Допустимые значения ?sort= и соответствующие колонки
+// ORDER BY нельзя параметризовать, поэтому в SQL попадают только имена колонок из этого списка.
+var sortColumns = map[string]string{
+	"createdAt":    "created_at",
+	"updatedAt":    "updated_at",
+	"dueDate":      "due_date",
+	"priority":     "priority",
+	"title":        "title",
+	"lastActivity": "last_activity_at",
+	"completedAt":  "completed_at",
+	"flag":         "NULLIF(flag, '')", // Задачи без флажка - в конце при любом направлении
+}
+
+// parseSort - Разобрать ?sort=field или ?sort=-field (по убыванию) в выражение ORDER BY
+func parseSort(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	direction := "ASC"
+	if strings.HasPrefix(raw, "-") {
+		direction = "DESC"
+		raw = raw[1:]
+	}
+	column, ok := sortColumns[raw]
+	if !ok {
+		return "", fmt.Errorf("unsupported sort field %q", raw)
+	}
+	return column + " " + direction + " NULLS LAST", nil
+}
+
+// Незавершенные задачи выше завершенных при любой ?sort= (COMPLETED_LAST, на запрос - ?completedLast=)
+var completedLastDefault = true
+
+// completedLast - Ставить ли завершенные задачи в конец списка для этого запроса
+func completedLast(c *gin.Context) bool {
+	if value, err := strconv.ParseBool(c.Query("completedLast")); err == nil {
+		return value
+	}
+	return completedLastDefault
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// sqlInjection - Классическая попытка внедрить SQL через параметр запроса
+const sqlInjection = "'; DROP TABLE tasks; --"
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: "", want: ""},
+		{raw: "dueDate", want: "due_date ASC NULLS LAST"},
+		{raw: "-createdAt", want: "created_at DESC NULLS LAST"},
+		{raw: " title ", want: "title ASC NULLS LAST"},
+		{raw: "flag", want: "NULLIF(flag, '') ASC NULLS LAST"},
+		{raw: "due_date", wantErr: true}, // Только имена из API, не колонки
+		{raw: "--createdAt", wantErr: true},
+		{raw: "id", wantErr: true},
+		{raw: sqlInjection, wantErr: true},
+		{raw: "-" + sqlInjection, wantErr: true},
+		{raw: "title; DROP TABLE tasks", wantErr: true},
+		{raw: "title ASC, (SELECT 1)", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseSort(tt.raw)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseSort(%q) = %q, want error", tt.raw, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("parseSort(%q) = %q, %v; want %q", tt.raw, got, err, tt.want)
+		}
+	}
+}
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "milk", want: "milk"},
+		{value: "100%", want: `100\%`},
+		{value: "snake_case", want: `snake\_case`},
+		{value: `C:\temp`, want: `C:\\temp`},
+		{value: `\%`, want: `\\\%`},
+		{value: sqlInjection, want: sqlInjection}, // Кавычки не трогаются: значение уходит параметром
+	}
+	for _, tt := range tests {
+		if got := escapeLike(tt.value); got != tt.want {
+			t.Errorf("escapeLike(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+// TestApplyTaskFilterBindsInput - Пользовательские значения фильтра попадают в SQL только параметрами
+func TestApplyTaskFilterBindsInput(t *testing.T) {
+	dry := dryRunDB(t)
+	filter, err := parseTaskFilter(url.Values{"search": {sqlInjection}, "tag": {sqlInjection}, "assignee": {sqlInjection}})
+	if err != nil {
+		t.Fatalf("parseTaskFilter: %v", err)
+	}
+	var tasks []Task
+	stmt := applyTaskFilter(dry.Model(&Task{}), filter).Find(&tasks).Statement
+	sql := stmt.SQL.String()
+	if strings.Contains(sql, "DROP") || strings.Contains(sql, "'; ") {
+		t.Fatalf("user input is inlined into SQL: %s", sql)
+	}
+	if !strings.Contains(sql, "ILIKE $1") {
+		t.Errorf("search is not bound as a parameter: %s", sql)
+	}
+	bound := 0
+	for _, v := range stmt.Vars {
+		if s, ok := v.(string); ok && strings.Contains(s, sqlInjection) {
+			bound++
+		}
+	}
+	// Поиск по названию и описанию, тег и исполнитель
+	if bound != 4 {
+		t.Errorf("%d parameters carry the input, want 4; vars: %v", bound, stmt.Vars)
+	}
+}
+
+// TestListRejectsInjection - ?sort= с SQL отклоняется, ?search= ищется буквально, а таблица остается на месте
+func TestListRejectsInjection(t *testing.T) {
+	setupTestDB(t)
+	createTestTask(t, Task{Title: "Обычная задача"})
+	match := createTestTask(t, Task{Title: "Задача " + sqlInjection})
+
+	w := performRequest(http.MethodGet, "/tasks/?sort="+url.QueryEscape(sqlInjection), "", "")
+	expectStatus(t, w, http.StatusBadRequest)
+
+	w = performRequest(http.MethodGet, "/tasks/?search="+url.QueryEscape(sqlInjection), "", "")
+	expectStatus(t, w, http.StatusOK)
+	if ids := responseTaskIDs(t, w); len(ids) != 1 || ids[0] != match.ID {
+		t.Errorf("search returned tasks %v, want only %d", ids, match.ID)
+	}
+
+	var count int64
+	if err := db.Model(&Task{}).Count(&count).Error; err != nil || count != 2 {
+		t.Errorf("tasks after requests: count %d, err %v", count, err)
+	}
+}
+
+// TestTagFilterListAndExport - ?tag= находит только целый тег (не подстроку, "%" буквально),
+// одинаково в списке и в экспорте; тег с запятой отклоняется
+func TestTagFilterListAndExport(t *testing.T) {
+	setupTestDB(t)
+	urgent := createTestTask(t, Task{Title: "Urgent", Tags: "работа, срочно, Home"})
+	createTestTask(t, Task{Title: "Not urgent", Tags: "несрочно"})
+	percent := createTestTask(t, Task{Title: "Sale", Tags: "100%,скидка"})
+	createTestTask(t, Task{Title: "Thousand", Tags: "1000"})
+
+	exportIDs := func(t *testing.T, query string) []uint {
+		t.Helper()
+		w := performRequest(http.MethodGet, "/tasks/export?format=json&"+query, "", "")
+		expectStatus(t, w, http.StatusOK)
+		var tasks []Task
+		if err := json.Unmarshal(w.Body.Bytes(), &tasks); err != nil {
+			t.Fatalf("decode export: %v", err)
+		}
+		ids := make([]uint, len(tasks))
+		for i, task := range tasks {
+			ids[i] = task.ID
+		}
+		return ids
+	}
+
+	tests := []struct {
+		tag  string
+		want []uint
+	}{
+		{tag: "срочно", want: []uint{urgent.ID}},
+		{tag: " HOME ", want: []uint{urgent.ID}},
+		{tag: "100%", want: []uint{percent.ID}},
+		{tag: "%", want: []uint{}},
+		{tag: "10_", want: []uint{}},
+		{tag: "работ", want: []uint{}},
+	}
+	for _, tt := range tests {
+		query := "tag=" + url.QueryEscape(tt.tag)
+		w := performRequest(http.MethodGet, "/tasks/?sort=createdAt&"+query, "", "")
+		expectStatus(t, w, http.StatusOK)
+		if got := responseTaskIDs(t, w); !slices.Equal(got, tt.want) {
+			t.Errorf("list ?tag=%q = %v, want %v", tt.tag, got, tt.want)
+		}
+		if got := exportIDs(t, query); !slices.Equal(got, tt.want) {
+			t.Errorf("export ?tag=%q = %v, want %v", tt.tag, got, tt.want)
+		}
+	}
+
+	for _, path := range []string{"/tasks/", "/tasks/export"} {
+		w := performRequest(http.MethodGet, path+"?tag="+url.QueryEscape("работа,срочно"), "", "")
+		expectStatus(t, w, http.StatusBadRequest)
+	}
+}
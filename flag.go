@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// --- Флажки задач ---
+
+// taskFlags - Именованные флажки; кроме них допускается любой цвет (#rgb или #rrggbb, как у тегов)
+var taskFlags = map[string]bool{
+	"star": true, "red": true, "orange": true, "yellow": true, "green": true, "blue": true, "purple": true,
+}
+
+// normalizeFlag - Привести флажок к форме, в которой он хранится (нижний регистр); пустая строка - без флажка
+func normalizeFlag(raw string) (string, error) {
+	flag := strings.ToLower(strings.TrimSpace(raw))
+	if flag == "" || taskFlags[flag] || hexColorPattern.MatchString(flag) {
+		return flag, nil
+	}
+	return "", fmt.Errorf("invalid flag %q, expected star, red, orange, yellow, green, blue, purple or a hex color like #ff9800", raw)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestNormalizeFlag(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: "", want: ""},
+		{raw: " Star ", want: "star"},
+		{raw: "#FF9800", want: "#ff9800"},
+		{raw: "#abc", want: "#abc"},
+		{raw: "pink", wantErr: true},
+		{raw: "#12345", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := normalizeFlag(tt.raw)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("normalizeFlag(%q) = %q, %v; want %q, error %v", tt.raw, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// TestFlagFilterAndSort - ?flag= выбирает задачи с флажком или без него (none),
+// а ?sort=flag ставит задачи без флажка последними в обоих направлениях
+func TestFlagFilterAndSort(t *testing.T) {
+	setupTestDB(t)
+	red := createTestTask(t, Task{Title: "Красная", Flag: "red"})
+	none := createTestTask(t, Task{Title: "Без флажка"})
+	blue := createTestTask(t, Task{Title: "Синяя", Flag: "blue"})
+
+	for _, tt := range []struct {
+		query string
+		want  []uint
+	}{
+		{"flag=RED", []uint{red.ID}},
+		{"flag=none", []uint{none.ID}},
+		{"sort=flag", []uint{blue.ID, red.ID, none.ID}},
+		{"sort=-flag", []uint{red.ID, blue.ID, none.ID}},
+	} {
+		w := performRequest(http.MethodGet, "/tasks/?"+tt.query, "", "")
+		expectStatus(t, w, http.StatusOK)
+		if got := responseTaskIDs(t, w); !slices.Equal(got, tt.want) {
+			t.Errorf("?%s = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+	expectStatus(t, performRequest(http.MethodGet, "/tasks/?flag=pink", "", ""), http.StatusBadRequest)
+	expectStatus(t, performRequest(http.MethodPost, "/tasks/", `{"title": "Розовая", "flag": "pink"}`, ""), http.StatusBadRequest)
+}
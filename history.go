@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// --- История изменений задачи ---
+
+// TaskSnapshot - Содержательные поля задачи на момент сохранения
+// Порядок полей задает порядок изменений в ответе diff.
+type TaskSnapshot struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Priority    string     `json:"priority"`
+	Status      string     `json:"status"`
+	IsCompleted bool       `json:"isCompleted"`
+	DueDate     *time.Time `json:"dueDate"`
+	Tags        string     `json:"tags"`
+	ParentID    *uint      `json:"parentId"`
+	AssigneeID  *string    `json:"assigneeId"`
+	ArchivedAt  *time.Time `json:"archivedAt"`
+}
+
+// TaskHistory - Версия задачи: снимок полей после очередного сохранения
+type TaskHistory struct {
+	ID        uint         `json:"-" gorm:"primaryKey"`
+	TaskID    uint         `json:"taskId" gorm:"index"`
+	Version   int          `json:"version"`
+	Snapshot  TaskSnapshot `json:"snapshot" gorm:"serializer:json"`
+	ChangedAt time.Time    `json:"changedAt"`
+}
+
+// TableName - История хранится в таблице task_history
+func (TaskHistory) TableName() string {
+	return "task_history"
+}
+
+// snapshot - Снимок содержательных полей задачи
+func (t *Task) snapshot() TaskSnapshot {
+	return TaskSnapshot{
+		Title: t.Title, Description: t.Description, Priority: t.Priority,
+		Status: t.Status, IsCompleted: t.IsCompleted, DueDate: t.DueDate, Tags: t.Tags,
+		ParentID: t.ParentID, AssigneeID: t.AssigneeID, ArchivedAt: t.ArchivedAt,
+	}
+}
+
+// recordHistory - Записать новую версию, если содержимое задачи изменилось
+// Сохранения, меняющие только служебные поля (позицию, время активности), версий не создают.
+func recordHistory(tx *gorm.DB, task *Task) error {
+	current := task.snapshot()
+	var latest TaskHistory
+	err := tx.Where("task_id = ?", task.ID).Order("version DESC").Limit(1).Find(&latest).Error
+	if err != nil {
+		return err
+	}
+	if latest.ID != 0 && reflect.DeepEqual(normalizeSnapshot(latest.Snapshot), normalizeSnapshot(current)) {
+		return nil
+	}
+	return tx.Create(&TaskHistory{
+		TaskID:    task.ID,
+		Version:   latest.Version + 1,
+		Snapshot:  current,
+		ChangedAt: time.Now(),
+	}).Error
+}
+
+// normalizeSnapshot - Привести время к UTC без монотонных часов, чтобы снимки можно было сравнивать
+func normalizeSnapshot(s TaskSnapshot) TaskSnapshot {
+	for _, t := range []**time.Time{&s.DueDate, &s.ArchivedAt} {
+		if *t != nil {
+			utc := (*t).UTC().Round(time.Microsecond)
+			*t = &utc
+		}
+	}
+	return s
+}
+
+// FieldChange - Изменение одного поля между версиями
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// diffSnapshots - Поля, различающиеся в двух снимках (previous == nil - все поля новой версии)
+func diffSnapshots(previous *TaskSnapshot, current TaskSnapshot) []FieldChange {
+	var prevFields map[string]interface{}
+	if previous != nil {
+		prevFields = snapshotFields(normalizeSnapshot(*previous))
+	}
+	changes := []FieldChange{}
+	currFields := snapshotFields(normalizeSnapshot(current))
+	typ := reflect.TypeOf(current)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i).Tag.Get("json")
+		from, to := prevFields[field], currFields[field]
+		if !reflect.DeepEqual(from, to) {
+			changes = append(changes, FieldChange{Field: field, From: from, To: to})
+		}
+	}
+	return changes
+}
+
+// snapshotFields - Снимок как map поле -> значение (в том виде, в каком он уходит в JSON)
+func snapshotFields(s TaskSnapshot) map[string]interface{} {
+	raw, _ := json.Marshal(s)
+	var fields map[string]interface{}
+	json.Unmarshal(raw, &fields)
+	return fields
+}
+
+// GetTaskHistory - Все версии задачи, от новых к старым (GET /tasks/:id/history)
+func GetTaskHistory(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var versions []TaskHistory
+	if result := txFromContext(c).Where("task_id = ?", id).Order("version DESC").Find(&versions); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load task history"})
+		return
+	}
+	if len(versions) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task history not found"})
+		return
+	}
+	c.JSON(http.StatusOK, versions)
+}
+
+// GetTaskHistoryDiff - Изменения полей между версией и предыдущей (GET /tasks/:id/history/:version/diff)
+// Для первой версии предыдущей нет, и все поля считаются измененными с null.
+func GetTaskHistoryDiff(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid version", "code": "invalid_id"})
+		return
+	}
+
+	var entries []TaskHistory
+	if result := txFromContext(c).Where("task_id = ? AND version IN ?", id, []int{version - 1, version}).Order("version").Find(&entries); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load task history"})
+		return
+	}
+	if len(entries) == 0 || entries[len(entries)-1].Version != version {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Version not found"})
+		return
+	}
+
+	current := entries[len(entries)-1]
+	var previous *TaskSnapshot
+	response := gin.H{"taskId": id, "version": version, "changedAt": current.ChangedAt}
+	if len(entries) == 2 {
+		previous = &entries[0].Snapshot
+		response["previousVersion"] = entries[0].Version
+	}
+	response["changes"] = diffSnapshots(previous, current.Snapshot)
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// --- Хуки жизненного цикла задачи (GORM) ---
+
+// BeforeSave - Отметить последнюю активность по задаче
+// SetColumn работает и для сохранения структуры, и для Update/Updates с map.
+func (t *Task) BeforeSave(tx *gorm.DB) error {
+	tx.Statement.SetColumn("LastActivityAt", time.Now())
+	return nil
+}
+
+// AfterFind - Запомнить загруженный статус, чтобы reconcileStatus мог проверить переход
+func (t *Task) AfterFind(tx *gorm.DB) error {
+	t.storedStatus = t.Status
+	t.storedCompleted = t.IsCompleted
+	t.storedCompletedAt = t.CompletedAt
+	return nil
+}
+
+// AfterSave - Вызывается после создания и обновления задачи
+func (t *Task) AfterSave(tx *gorm.DB) error {
+	invalidateTaskCachesOnCommit(tx.Statement.Context, t.ID)
+
+	// Версия в истории изменений (массовые обновления по условию не знают id и не записываются)
+	if t.ID != 0 {
+		if err := recordHistory(tx.Session(&gorm.Session{NewDB: true}), t); err != nil {
+			return err
+		}
+	}
+
+	// При включенном AUTO_COMPLETE_PARENT завершаем родителя, когда завершена последняя подзадача
+	if autoCompleteParent && t.IsCompleted && t.ParentID != nil {
+		return completeParentIfDone(tx.Session(&gorm.Session{NewDB: true}), *t.ParentID)
+	}
+	return nil
+}
+
+// AfterCreate - Событие task.created для вебхуков (в той же транзакции, что и создание)
+// Upsert проходит через Create и при конфликте обновляет строку, его событие ставит upsertByExternalID.
+func (t *Task) AfterCreate(tx *gorm.DB) error {
+	if t.upserting {
+		return nil
+	}
+	return enqueueWebhookEvent(tx.Session(&gorm.Session{NewDB: true}), webhookTaskCreated, t)
+}
+
+// AfterUpdate - Событие task.updated для вебхуков
+func (t *Task) AfterUpdate(tx *gorm.DB) error {
+	return enqueueWebhookEvent(tx.Session(&gorm.Session{NewDB: true}), webhookTaskUpdated, t)
+}
+
+// AfterDelete - Вызывается после удаления задачи (в том числе мягкого)
+func (t *Task) AfterDelete(tx *gorm.DB) error {
+	invalidateTaskCachesOnCommit(tx.Statement.Context, t.ID)
+	return enqueueWebhookEvent(tx.Session(&gorm.Session{NewDB: true}), webhookTaskDeleted, t)
+}
+
+// invalidateTaskCaches - Сбросить все кэши, зависящие от содержимого задач
+// id 0 - изменение по условию (массовое обновление или удаление), затронута может быть любая задача.
+func invalidateTaskCaches(id uint) {
+	taskStatsCache.invalidate()
+	taskCache.remove(id)
+}
+
+// AfterSave - Запись учета времени тоже считается активностью по задаче
+func (e *TimeEntry) AfterSave(tx *gorm.DB) error {
+	invalidateTaskCachesOnCommit(tx.Statement.Context, e.TaskID)
+	return tx.Session(&gorm.Session{NewDB: true}).Model(&Task{}).
+		Where("id = ?", e.TaskID).
+		UpdateColumn("last_activity_at", time.Now()).Error
+}
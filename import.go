@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// --- Массовый импорт задач ---
+
+const (
+	maxImportRows   = 5000 // Максимум строк в одном запросе импорта
+	importBatchSize = 100  // Строк в одной транзакции
+)
+
+// Итог импорта строки
+const (
+	importCreated = "created"
+	importUpdated = "updated"
+	importSkipped = "skipped"
+)
+
+// ImportRowResult - Результат импорта одной строки
+type ImportRowResult struct {
+	Index      int               `json:"index"`
+	ExternalID *string           `json:"externalId,omitempty"`
+	ID         uint              `json:"id,omitempty"`
+	Result     string            `json:"result"`           // created, updated или skipped
+	Error      string            `json:"error,omitempty"`  // Причина пропуска (пусто, если задача не изменилась)
+	Errors     map[string]string `json:"errors,omitempty"` // Ошибки проверки по полям, как в ответе POST /tasks
+}
+
+// errImportLimit - Строка не создана: достигнут лимит задач пользователя
+var errImportLimit = errors.New("task limit reached")
+
+// importQuota - Сколько задач еще может создать пользователь (nil - без ограничения)
+type importQuota struct {
+	remaining *int64
+}
+
+// take - Занять место под новую задачу
+func (q importQuota) take() bool {
+	if q.remaining == nil {
+		return true
+	}
+	if *q.remaining <= 0 {
+		return false
+	}
+	*q.remaining--
+	return true
+}
+
+// give - Вернуть место, занятое под задачу, которая так и не была записана
+func (q importQuota) give() {
+	if q.remaining != nil {
+		*q.remaining++
+	}
+}
+
+// importRowError - Понятная причина ошибки записи строки
+func importRowError(err error) string {
+	if errors.Is(err, errImportLimit) {
+		return err.Error()
+	}
+	if isDuplicateTitle(err) {
+		return "a task with this title already exists"
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return "externalId already exists"
+	}
+	return "failed to save task"
+}
+
+// importTask - Записать одну задачу; при upsert строка с тем же externalId обновляется,
+// а если она не изменилась бы, строка пропускается. Новая и восстановленная из корзины
+// задачи занимают место в лимите; если запись не удалась, место возвращается.
+func importTask(tx *gorm.DB, task *Task, upsert bool, quota importQuota) (string, error) {
+	if upsert && task.ExternalID != nil {
+		var existing Task
+		found := tx.Unscoped().Where("external_id = ?", *task.ExternalID).Limit(1).Find(&existing).RowsAffected > 0
+		active := found && !existing.DeletedAt.Valid
+		if active {
+			incoming := task.snapshot()
+			incoming.ArchivedAt = existing.ArchivedAt // Архивность импортом не меняется
+			if reflect.DeepEqual(normalizeSnapshot(existing.snapshot()), normalizeSnapshot(incoming)) {
+				task.ID = existing.ID
+				return importSkipped, nil
+			}
+		}
+		if !active && !quota.take() {
+			return importSkipped, errImportLimit
+		}
+		if !found {
+			task.Position = nextPosition(tx, task.ParentID)
+		}
+		if err := upsertByExternalID(tx, task); err != nil {
+			if !active {
+				quota.give()
+			}
+			return importSkipped, err
+		}
+		if active {
+			return importUpdated, nil
+		}
+		return importCreated, nil
+	}
+
+	if !quota.take() {
+		return importSkipped, errImportLimit
+	}
+	task.Position = nextPosition(tx, task.ParentID)
+	if err := tx.Create(task).Error; err != nil {
+		quota.give()
+		return importSkipped, err
+	}
+	return importCreated, nil
+}
+
+// ImportTasks - Массовый импорт задач (POST /tasks/import?upsert=true)
+// Тело: {"tasks": [{...}, ...]} в формате задачи. С ?upsert=true строки с externalId
+// обновляют существующие задачи вместо создания дублей. Строки пишутся пачками по
+// importBatchSize в транзакции; ошибка в строке откатывает только ее (savepoint),
+// а в ответе для каждой строки указано created, updated или skipped.
+// ?mode=partial отвечает так же, но статусом 207 Multi-Status; ?mode=atomic пишет все
+// строки одной транзакцией и при ошибке любой строки откатывает весь импорт (400).
+func ImportTasks(c *gin.Context) {
+	mode, ok := batchMode(c, "")
+	if !ok {
+		return
+	}
+	upsert := c.Query("upsert") == "true"
+	strict := strictFields(c) // Строгий режим действует и на каждую строку
+	var requestBody struct {
+		Tasks []json.RawMessage `json:"tasks" binding:"required"`
+	}
+	if err := bindJSON(c, &requestBody); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if len(requestBody.Tasks) > maxImportRows {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Too many tasks in one import", "limit": maxImportRows})
+		return
+	}
+
+	userID := currentUserID(c)
+	quota := importQuota{}
+	if maxTasksPerUser > 0 && userID != nil {
+		count, err := userTaskCount(txFromContext(c), *userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count tasks"})
+			return
+		}
+		remaining := int64(maxTasksPerUser) - count
+		quota.remaining = &remaining
+	}
+
+	results := make([]ImportRowResult, len(requestBody.Tasks))
+	if mode == batchAtomic {
+		importTasksAtomically(c, requestBody.Tasks, results, userID, upsert, quota, strict)
+		return
+	}
+	for start := 0; start < len(requestBody.Tasks); start += importBatchSize {
+		end := min(start+importBatchSize, len(requestBody.Tasks))
+		err := txFromContext(c).Transaction(func(tx *gorm.DB) error {
+			for i := start; i < end; i++ {
+				results[i] = importRow(tx, i, requestBody.Tasks[i], userID, upsert, quota, strict)
+			}
+			return nil
+		})
+		if err != nil {
+			for i := start; i < end; i++ {
+				if results[i].Result == importCreated {
+					quota.give() // Пачка откатилась: созданная в ней задача не записана
+				}
+				results[i] = ImportRowResult{Index: i, ExternalID: results[i].ExternalID, Result: importSkipped, Error: "batch failed"}
+			}
+		}
+	}
+	counts := map[string]int{importCreated: 0, importUpdated: 0, importSkipped: 0}
+	var imported []uint
+	for _, result := range results {
+		counts[result.Result]++
+		if result.Result != importSkipped {
+			imported = append(imported, result.ID)
+		}
+	}
+	// Пачки уже зафиксированы: повторный сброс не дает параллельному чтению оставить в кэше строки до импорта
+	if len(imported) > 0 {
+		invalidateTaskCachesOnCommit(c.Request.Context(), imported...)
+	}
+	status := http.StatusOK
+	if mode == batchPartial {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, gin.H{
+		"created": counts[importCreated],
+		"updated": counts[importUpdated],
+		"skipped": counts[importSkipped],
+		"results": results,
+	})
+}
+
+// importTasksAtomically - Записать все строки одной транзакцией: ошибка любой строки откатывает
+// весь импорт, а записанные до нее строки в ответе помечаются как откатанные
+func importTasksAtomically(c *gin.Context, rows []json.RawMessage, results []ImportRowResult, userID *string, upsert bool, quota importQuota, strict bool) {
+	errRowFailed := errors.New("import row failed")
+	err := txFromContext(c).Transaction(func(tx *gorm.DB) error {
+		failed := false
+		for i, raw := range rows {
+			results[i] = importRow(tx, i, raw, userID, upsert, quota, strict)
+			failed = failed || results[i].Error != ""
+		}
+		if failed {
+			return errRowFailed
+		}
+		return nil
+	})
+	if err == nil {
+		counts := map[string]int{importCreated: 0, importUpdated: 0, importSkipped: 0}
+		for _, result := range results {
+			counts[result.Result]++
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"created": counts[importCreated],
+			"updated": counts[importUpdated],
+			"skipped": counts[importSkipped],
+			"results": results,
+		})
+		return
+	}
+
+	if !errors.Is(err, errRowFailed) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import tasks"})
+		return
+	}
+	for i, result := range results {
+		if result.Error == "" {
+			results[i] = ImportRowResult{Index: i, ExternalID: result.ExternalID, Result: importSkipped, Error: "rolled back: another row failed"}
+		}
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "Import failed; no tasks were imported", "code": "import_failed", "results": results})
+}
+
+// importRow - Разобрать, проверить и записать одну строку импорта во вложенной транзакции
+func importRow(tx *gorm.DB, index int, raw json.RawMessage, userID *string, upsert bool, quota importQuota, strict bool) ImportRowResult {
+	result := ImportRowResult{Index: index, Result: importSkipped}
+	var task Task
+	if err := decodeJSON(bytes.NewReader(raw), &task, strict); err != nil {
+		result.Error = "invalid task JSON: " + err.Error()
+		result.Errors = validationErrors(err)
+		return result
+	}
+	result.ExternalID = task.ExternalID
+	task.ID = 0
+	task.CreatedBy = userID
+	task.ArchivedAt = nil
+	task.Pinned = false
+	if strings.TrimSpace(task.Title) == "" {
+		result.Error = "title is required"
+		result.Errors = map[string]string{"title": "is required"}
+		return result
+	}
+	if err := validateTask(tx, &task); err != nil {
+		result.Error = err.Error()
+		result.Errors = validationErrors(err)
+		return result
+	}
+
+	// Вложенная транзакция - savepoint: ошибка строки не прерывает пачку
+	err := tx.Transaction(func(rowTx *gorm.DB) error {
+		outcome, err := importTask(rowTx, &task, upsert, quota)
+		result.Result = outcome
+		return err
+	})
+	if err != nil {
+		result.Result = importSkipped
+		result.Error = importRowError(err)
+		return result
+	}
+	result.ID = task.ID
+	return result
+}
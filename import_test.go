@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// importResults - Разобрать результаты строк из ответа POST /tasks/import
+func importResults(t *testing.T, body []byte) []ImportRowResult {
+	t.Helper()
+	var response struct {
+		Results []ImportRowResult `json:"results"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatalf("decode import response: %v", err)
+	}
+	return response.Results
+}
+
+// setTaskLimit - Установить MAX_TASKS_PER_USER на время теста
+func setTaskLimit(t *testing.T, limit int) {
+	saved := maxTasksPerUser
+	maxTasksPerUser = limit
+	t.Cleanup(func() { maxTasksPerUser = saved })
+}
+
+// TestImportFailedRowsKeepQuota - Строка, которая не записалась, не занимает место в лимите,
+// поэтому следующие корректные строки импортируются
+func TestImportFailedRowsKeepQuota(t *testing.T) {
+	setupTestDB(t)
+	setTaskLimit(t, 2)
+	createTestTask(t, Task{Title: "Занято", CreatedBy: stringPtr("bob")})
+
+	body := `{"tasks": [{"title": "Занято"}, {"title": "Занято"}, {"title": "Первая"}, {"title": "Вторая"}, {"title": "Третья"}]}`
+	w := performRequest(http.MethodPost, "/tasks/import", body, "alice")
+	expectStatus(t, w, http.StatusOK)
+
+	want := []string{importSkipped, importSkipped, importCreated, importCreated, importSkipped}
+	for i, result := range importResults(t, w.Body.Bytes()) {
+		if result.Result != want[i] {
+			t.Errorf("row %d = %s (%s), want %s", i, result.Result, result.Error, want[i])
+		}
+	}
+	if results := importResults(t, w.Body.Bytes()); results[4].Error != errImportLimit.Error() {
+		t.Errorf("row over the limit: error %q, want %q", results[4].Error, errImportLimit.Error())
+	}
+}
+
+// TestImportRestoreTakesQuota - Восстановление удаленной задачи через upsert занимает место в лимите
+func TestImportRestoreTakesQuota(t *testing.T) {
+	setupTestDB(t)
+	setTaskLimit(t, 1)
+	deleted := createTestTask(t, Task{Title: "Удаленная", ExternalID: stringPtr("ext-1"), CreatedBy: stringPtr("alice")})
+	if err := db.Delete(&deleted).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	body := `{"tasks": [{"title": "Удаленная", "externalId": "ext-1"}, {"title": "Новая", "externalId": "ext-2"}]}`
+	w := performRequest(http.MethodPost, "/tasks/import?upsert=true", body, "alice")
+	expectStatus(t, w, http.StatusOK)
+
+	results := importResults(t, w.Body.Bytes())
+	if results[0].Result != importCreated || results[0].ID != deleted.ID {
+		t.Errorf("restored row = %+v, want created with id %d", results[0], deleted.ID)
+	}
+	if results[1].Result != importSkipped || results[1].Error != errImportLimit.Error() {
+		t.Errorf("row over the limit = %+v, want skipped by the limit", results[1])
+	}
+}
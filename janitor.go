@@ -0,0 +1,57 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// --- Очистка мягко удаленных задач ---
+
+// purgeDeletedTasks - Окончательно удалить задачи, мягко удаленные раньше чем retention назад
+func purgeDeletedTasks(tx *gorm.DB, retention time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-retention)
+	result := tx.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Delete(&Task{})
+	return result.RowsAffected, result.Error
+}
+
+// deletedRetention - Срок хранения удаленных задач (DELETED_RETENTION_DAYS, по умолчанию 30 дней)
+func deletedRetention() time.Duration {
+	return time.Duration(getEnvInt("DELETED_RETENTION_DAYS", 30)) * 24 * time.Hour
+}
+
+// runDeletedTasksJanitor - Периодически очищать корзину (интервал задается PURGE_INTERVAL)
+func runDeletedTasksJanitor() {
+	interval := getEnvDuration("PURGE_INTERVAL", time.Hour)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if readOnly.Load() {
+			continue
+		}
+		purged, err := purgeDeletedTasks(db, deletedRetention())
+		if err != nil {
+			log.Printf("Deleted tasks janitor failed: %v", err)
+			continue
+		}
+		log.Printf("Deleted tasks janitor purged %d task(s)", purged)
+		if err := purgeExpiredUndoTokens(); err != nil {
+			log.Printf("Failed to purge expired undo tokens: %v", err)
+		}
+	}
+}
+
+// PurgeDeletedTasks - Ручной запуск очистки корзины
+func PurgeDeletedTasks(c *gin.Context) {
+	purged, err := purgeDeletedTasks(txFromContext(c), deletedRetention())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to purge deleted tasks"})
+		return
+	}
+	log.Printf("Manual purge removed %d deleted task(s)", purged)
+	c.JSON(http.StatusOK, gin.H{"purged": purged})
+}
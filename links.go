@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// --- Ссылки задач ---
+
+// TaskLink - Ссылка на внешний ресурс (тикет, документ), прикрепленная к задаче
+type TaskLink struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	TaskID    uint      `json:"taskId" gorm:"index"`
+	URL       string    `json:"url"`
+	Title     string    `json:"title"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Ограничения длины: URL в байтах, название в символах
+const (
+	maxLinkURLLength   = 2048
+	maxLinkTitleLength = 200
+)
+
+// validateLinkURL - Проверить, что ссылка - абсолютный http(s)-URL
+func validateLinkURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", errors.New("url is required")
+	}
+	if len(raw) > maxLinkURLLength {
+		return "", errors.New("url is too long")
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", errors.New("url must be an absolute http or https URL")
+	}
+	return parsed.String(), nil
+}
+
+// attachLinks - Загрузить ссылки для списка задач одним запросом
+func attachLinks(tx *gorm.DB, tasks []Task) {
+	if len(tasks) == 0 {
+		return
+	}
+	ids := make([]uint, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	var links []TaskLink
+	if err := tx.Where("task_id IN ?", ids).Order("task_id, id").Find(&links).Error; err != nil {
+		log.Printf("Failed to load task links: %v", err)
+		return
+	}
+	byTask := make(map[uint][]TaskLink)
+	for _, link := range links {
+		byTask[link.TaskID] = append(byTask[link.TaskID], link)
+	}
+	for i := range tasks {
+		tasks[i].Links = byTask[tasks[i].ID]
+	}
+}
+
+// AddTaskLink - Прикрепить ссылку к задаче (POST /tasks/:id/links)
+// Тело: {"url": "https://...", "title": "..."}, title необязателен.
+func AddTaskLink(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var task Task
+	if result := txFromContext(c).First(&task, id); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	var requestBody struct {
+		URL   string `json:"url"`
+		Title string `json:"title"`
+	}
+	if err := bindJSON(c, &requestBody); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	linkURL, err := validateLinkURL(requestBody.URL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "invalid_url"})
+		return
+	}
+	title := strings.TrimSpace(requestBody.Title)
+	if len([]rune(title)) > maxLinkTitleLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title is too long"})
+		return
+	}
+
+	link := TaskLink{TaskID: task.ID, URL: linkURL, Title: title}
+	err = txFromContext(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&link).Error; err != nil {
+			return err
+		}
+		return touchTask(tx, task.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add link"})
+		return
+	}
+	c.JSON(http.StatusCreated, link)
+}
+
+// DeleteTaskLink - Удалить ссылку (DELETE /tasks/:id/links/:linkId)
+func DeleteTaskLink(c *gin.Context) {
+	taskID, ok := parseID(c)
+	if !ok {
+		return
+	}
+	linkID, err := strconv.ParseUint(c.Param("linkId"), 10, 64)
+	if err != nil || linkID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid link id", "code": "invalid_id"})
+		return
+	}
+
+	var deleted int64
+	err = txFromContext(c).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("task_id = ?", taskID).Delete(&TaskLink{}, linkID)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+		return touchTask(tx, taskID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete link"})
+		return
+	}
+	if deleted == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Link not found"})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
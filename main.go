@@ -1,13 +1,21 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -15,21 +23,168 @@ import (
 
 // --- Структура данных для Задачи (Task) ---
 type Task struct {
-	ID          uint       `json:"id" gorm:"primaryKey"`
-	Title       string     `json:"title" binding:"required"`
-	Description string     `json:"description"`
-	Priority    string     `json:"priority"` // e.g., "высокий", "средний", "низкий"
-	DueDate     *time.Time `json:"dueDate"`  // Optional due date
-	Tags        string     `json:"tags"`     // Comma-separated tags, e.g., "проект X, срочно"
-	IsCompleted bool       `json:"isCompleted"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	UpdatedAt   time.Time  `json:"updatedAt"`
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	Title          string         `json:"title" binding:"required"`
+	Description    string         `json:"description,omitempty"`
+	Priority       string         `json:"priority"`                   // "high", "medium", "low" (на входе принимаются и "высокий", "средний", "низкий")
+	DueDate        *time.Time     `json:"dueDate"`                    // Optional due date
+	Tags           string         `json:"tags"`                       // Comma-separated tags, e.g., "проект X, срочно"
+	Status         string         `json:"status" gorm:"default:todo"` // todo, in_progress, blocked, done
+	IsCompleted    bool           `json:"isCompleted"`                // Производное: status == "done"
+	ParentID       *uint          `json:"parentId" gorm:"index"`      // Родительская задача (для подзадач)
+	Position       int            `json:"position"`                   // Порядок среди задач с тем же родителем
+	ExternalID     *string        `json:"externalId"`                 // ID задачи во внешней системе (для синхронизации)
+	ArchivedAt     *time.Time     `json:"archivedAt"`                 // Время архивации (nil - задача не в архиве)
+	Pinned         bool           `json:"pinned"`                     // Закреплена вверху списков (POST /tasks/:id/pin)
+	Flag           string         `json:"flag"`                       // Флажок: star, red, ... или цвет #rrggbb (пусто - без флажка)
+	CompletedAt    *time.Time     `json:"completedAt"`                // Когда задача перешла в done (nil - не завершена), ставится сервером
+	CreatedBy      *string        `json:"createdBy" gorm:"<-:create"` // Кто создал задачу (из заголовка USER_HEADER), не меняется
+	AssigneeID     *string        `json:"assigneeId"`                 // Исполнитель (ID пользователя), nil - не назначен
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+	LastActivityAt time.Time      `json:"lastActivityAt"` // Изменение задачи или связанных записей (учет времени)
+	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"` // Мягкое удаление
+
+	Progress           *float64               `json:"progress,omitempty" gorm:"-"`           // Доля завершенных подзадач (0..1), вычисляется
+	TotalTimeSpent     int64                  `json:"totalTimeSpent" gorm:"-"`               // Затраченное время в секундах, вычисляется
+	DescriptionPreview string                 `json:"descriptionPreview,omitempty" gorm:"-"` // Начало описания в списках
+	PriorityLabel      string                 `json:"priorityLabel,omitempty" gorm:"-"`      // Название приоритета на языке запроса
+	PriorityColor      string                 `json:"priorityColor,omitempty" gorm:"-"`      // Цвет приоритета (PRIORITY_COLORS)
+	Expanded           map[string]interface{} `json:"expand,omitempty" gorm:"-"`             // Расширения, запрошенные через ?expand=
+	Checklist          []ChecklistItem        `json:"checklist,omitempty" gorm:"-"`
+	ChecklistProgress  *float64               `json:"checklistProgress,omitempty" gorm:"-"` // Доля отмеченных пунктов (0..1)
+	NeedsReview        bool                   `json:"needsReview" gorm:"-"`                 // Не заполнены ключевые поля (см. review.go)
+	Links              []TaskLink             `json:"links,omitempty" gorm:"-"`             // Прикрепленные ссылки
+
+	// Статус на момент загрузки из БД (AfterFind), для проверки переходов
+	storedStatus      string
+	storedCompleted   bool
+	storedCompletedAt *time.Time
+
+	upserting bool // Запись через upsertByExternalID: событие вебхука выбирается после записи
 }
 
 var db *gorm.DB // Глобальная переменная для подключения к БД
 
+// Порог похожести для нечеткого поиска (pg_trgm), переопределяется через FUZZY_THRESHOLD
+var fuzzyThreshold = 0.3
+
+// getEnv - Прочитать строковую переменную окружения или вернуть значение по умолчанию
+func getEnv(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
+
+// getEnvBool - Прочитать логическое значение из переменной окружения или вернуть значение по умолчанию
+func getEnvBool(key string, def bool) bool {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		log.Printf("Invalid %s value %q, using default %v", key, raw, def)
+		return def
+	}
+	return value
+}
+
+// getEnvInt - Прочитать целое значение из переменной окружения или вернуть значение по умолчанию
+func getEnvInt(key string, def int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		log.Printf("Invalid %s value %q, using default %v", key, raw, def)
+		return def
+	}
+	return value
+}
+
+// getEnvDuration - Прочитать длительность (например, "1h") из переменной окружения или вернуть значение по умолчанию
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	value, err := time.ParseDuration(raw)
+	if err != nil {
+		log.Printf("Invalid %s value %q, using default %v", key, raw, def)
+		return def
+	}
+	return value
+}
+
+// getEnvFloat - Прочитать дробное значение из переменной окружения или вернуть значение по умолчанию
+func getEnvFloat(key string, def float64) float64 {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		log.Printf("Invalid %s value %q, using default %v", key, raw, def)
+		return def
+	}
+	return value
+}
+
 // --- Инициализация базы данных ---
 func initDB() {
+	connectDB()
+
+	// Версионные миграции схемы базы данных
+	if err := runMigrations(); err != nil {
+		log.Fatalf("Failed to migrate database schema: %v", err)
+	}
+	log.Println("Database migration completed.")
+
+	fuzzyThreshold = getEnvFloat("FUZZY_THRESHOLD", fuzzyThreshold)
+	maxPaginationOffset = getEnvInt("MAX_PAGINATION_OFFSET", maxPaginationOffset)
+	configurePageSizes(getEnvInt("DEFAULT_PAGE_SIZE", defaultPageSize), getEnvInt("MAX_PAGE_SIZE", maxPageSize))
+	autoCompleteParent = getEnvBool("AUTO_COMPLETE_PARENT", autoCompleteParent)
+	statsCacheTTL = getEnvDuration("STATS_CACHE_TTL", statsCacheTTL)
+	weekStart = parseWeekStart(os.Getenv("WEEK_START"))
+	maxDescriptionLength = getEnvInt("MAX_DESCRIPTION_LENGTH", maxDescriptionLength)
+	userHeader = getEnv("USER_HEADER", userHeader)
+	basePath = strings.TrimRight(os.Getenv("BASE_PATH"), "/")
+	maxTasksPerUser = getEnvInt("MAX_TASKS_PER_USER", maxTasksPerUser)
+	taskLimitCountDeleted = getEnvBool("TASK_LIMIT_COUNT_DELETED", taskLimitCountDeleted)
+	taskLimitCountArchived = getEnvBool("TASK_LIMIT_COUNT_ARCHIVED", taskLimitCountArchived)
+	taskCache = newTaskLRU(getEnvInt("TASK_CACHE_SIZE", 0), getEnvDuration("TASK_CACHE_TTL", time.Minute))
+	defaultDueHour, defaultDueMinute = parseDefaultDueTime(os.Getenv("DEFAULT_DUE_TIME"))
+	fields, err := parseReviewFields(getEnv("NEEDS_REVIEW_FIELDS", strings.Join(reviewFields, ",")))
+	if err != nil {
+		log.Fatalf("Invalid NEEDS_REVIEW_FIELDS: %v", err)
+	}
+	reviewFields = fields
+	autoArchiveDays = getEnvInt("AUTO_ARCHIVE_DAYS", autoArchiveDays)
+	readOnly.Store(getEnvBool("READ_ONLY", false))
+	relatedLimit = getEnvInt("RELATED_LIMIT", relatedLimit)
+	completedLastDefault = getEnvBool("COMPLETED_LAST", completedLastDefault)
+	webhookMaxAttempts = getEnvInt("WEBHOOK_MAX_ATTEMPTS", webhookMaxAttempts)
+	maxPinnedTasks = getEnvInt("MAX_PINNED_TASKS", maxPinnedTasks)
+	pinnedFirstDefault = getEnvBool("PINNED_FIRST", pinnedFirstDefault)
+	requestTransactions = getEnvBool("REQUEST_TRANSACTIONS", requestTransactions)
+	strictFieldsDefault = getEnvBool("STRICT_FIELDS", strictFieldsDefault)
+	undoTTL = getEnvDuration("UNDO_TTL", undoTTL)
+	if defaultQuietHours, err = parseQuietHours(os.Getenv("QUIET_HOURS")); err != nil {
+		log.Fatalf("Invalid QUIET_HOURS: %v", err)
+	}
+	if priorityColors, err = parsePriorityColors(os.Getenv("PRIORITY_COLORS"), priorityColors); err != nil {
+		log.Fatalf("Invalid PRIORITY_COLORS: %v", err)
+	}
+	if autoArchiveUserDays, err = parseAutoArchiveUserDays(os.Getenv("AUTO_ARCHIVE_USER_DAYS")); err != nil {
+		log.Fatalf("Invalid AUTO_ARCHIVE_USER_DAYS: %v", err)
+	}
+}
+
+// connectDB - Подключение к базе данных по DATABASE_URL
+func connectDB() {
 	// Загружаем переменные окружения из .env файла (только для локальной разработки)
 	// В Docker Compose они будут передаваться напрямую через environment
 	if err := godotenv.Load(); err != nil {
@@ -42,126 +197,924 @@ func initDB() {
 	}
 
 	var err error
-	db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	initLogLevel()
+	// Подключение проверяется явно в waitForDB, с повторами, а не единственной попыткой внутри Open
+	db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: newSlogGormLogger(), DisableAutomaticPing: true})
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
+	if err := waitForDB(db, getEnvInt("DB_CONNECT_ATTEMPTS", 5), getEnvDuration("DB_CONNECT_BACKOFF", time.Second)); err != nil {
+		log.Fatalf("Cannot connect to database %s: %v", describeDSN(dsn), err)
+	}
+	if err := registerTimingCallbacks(db); err != nil {
+		log.Fatalf("Failed to register timing callbacks: %v", err)
+	}
 
 	log.Println("Database connection established successfully.")
+}
 
-	// Автоматическая миграция схемы базы данных
-	// GORM создаст таблицы, если их нет
-	err = db.AutoMigrate(&Task{})
+// waitForDB - Дождаться, пока база ответит на ping (attempts попыток, пауза удваивается от backoff до 30s)
+// База в docker compose часто поднимается позже приложения; без этой проверки ошибка
+// всплывала бы посреди миграций, а не как понятное "cannot connect" при старте.
+func waitForDB(conn *gorm.DB, attempts int, backoff time.Duration) error {
+	sqlDB, err := conn.DB()
 	if err != nil {
-		log.Fatalf("Failed to migrate database schema: %v", err)
+		return err
 	}
-	log.Println("Database migration completed.")
+	attempts = max(attempts, 1)
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err = sqlDB.PingContext(ctx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		if attempt == attempts {
+			return fmt.Errorf("no response after %d attempts: %w", attempts, err)
+		}
+		log.Printf("Database is not reachable (attempt %d/%d): %v; retrying in %s", attempt, attempts, err, backoff)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+// describeDSN - Адрес базы для сообщений об ошибках, без пароля
+func describeDSN(dsn string) string {
+	config, err := pgconn.ParseConfig(dsn)
+	if err != nil {
+		return "(invalid DATABASE_URL)"
+	}
+	return fmt.Sprintf("%s:%d/%s", config.Host, config.Port, config.Database)
+}
+
+// --- Проверка и представление задачи ---
+
+// Префикс, под которым API доступно снаружи (BASE_PATH, например /api), для ссылок в Location
+var basePath = ""
+
+// resourceLocation - Канонический URL ресурса с учетом BASE_PATH
+func resourceLocation(collection string, id uint) string {
+	return fmt.Sprintf("%s/%s/%d", basePath, collection, id)
+}
+
+// Максимальная длина описания в символах (MAX_DESCRIPTION_LENGTH)
+var maxDescriptionLength = 10000
+
+// Длина превью описания в списках
+const descriptionPreviewLength = 200
+
+// validateTask - Общая проверка задачи перед сохранением
+func validateTask(tx *gorm.DB, task *Task) error {
+	priority, err := normalizePriority(task.Priority)
+	if err != nil {
+		return &fieldError{"priority", err}
+	}
+	task.Priority = priority
+
+	flag, err := normalizeFlag(task.Flag)
+	if err != nil {
+		return &fieldError{"flag", err}
+	}
+	task.Flag = flag
+
+	if err := task.reconcileStatus(); err != nil {
+		return &fieldError{"status", err}
+	}
+	if length := utf8.RuneCountInString(task.Description); length > maxDescriptionLength {
+		return &fieldError{"description", fmt.Errorf("description is too long: %d characters, maximum is %d", length, maxDescriptionLength)}
+	}
+	if err := validateParent(tx, task); err != nil {
+		return &fieldError{"parentId", err}
+	}
+	return nil
+}
+
+// Уникальный индекс: название активной задачи не повторяется у одного пользователя среди задач того же родителя
+const activeTitleIndex = "idx_tasks_active_title"
+
+// isDuplicateTitle - Нарушено ли ограничение уникальности названия
+func isDuplicateTitle(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == activeTitleIndex
+}
+
+// respondDuplicateTitle - Ответ 409 на повторяющееся название
+func respondDuplicateTitle(c *gin.Context) {
+	c.JSON(http.StatusConflict, gin.H{"error": "An active task with this title already exists", "code": "duplicate_title"})
+}
+
+// toListView - Заменить полное описание коротким превью (для списков)
+// Полное описание доступно через GET /tasks/:id или ?fullDescription=true.
+func (t *Task) toListView() {
+	runes := []rune(t.Description)
+	if len(runes) > descriptionPreviewLength {
+		t.DescriptionPreview = string(runes[:descriptionPreviewLength]) + "…"
+	} else {
+		t.DescriptionPreview = t.Description
+	}
+	t.Description = ""
 }
 
 // --- Обработчики API для задач (CRUD) ---
 
+// parseID - Разобрать числовой параметр пути :id
+// Для нечисловых значений отвечает 400 invalid_id, чтобы 404 означал только отсутствующую задачу.
+func parseID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid task id", "code": "invalid_id"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
 // CreateTask - Создать новую задачу
 func CreateTask(c *gin.Context) {
 	var task Task
-	if err := c.ShouldBindJSON(&task); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bindTaskJSON(c, &task); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	tx := txFromContext(c)
+	if err := validateTask(tx, &task); err != nil {
+		respondValidationError(c, err)
 		return
 	}
-	db.Create(&task)
+	if !checkTaskLimit(c, 1) {
+		return
+	}
+	task.CreatedBy = currentUserID(c)
+	task.Position = nextPosition(tx, task.ParentID)
+	if result := tx.Create(&task); result.Error != nil {
+		if isDuplicateTitle(result.Error) {
+			respondDuplicateTitle(c)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task"})
+		return
+	}
+	c.Header("Location", resourceLocation("tasks", task.ID))
 	c.JSON(http.StatusCreated, task)
 }
 
 // GetTasks - Получить список всех задач
+// Поддерживает фильтры ?search= (с ?fuzzy=true для поиска с опечатками),
+// готовые выборки ?filter=active|archived|completed|overdue|inbox,
+// ?priority=, ?flag=, ?completed=, ?status=, ?assignee=me|none|<id>, ?needsReview=, ?tag=, ?dueBefore=, ?dueAfter=,
+// окна ?createdWithin=7d и ?completedWithin=30d, OR-группы ?or=priority:высокий,priority:средний,
+// сортировку ?sort=lastActivity (минус перед именем - по убыванию; закрепленные всегда первыми,
+// а незавершенные выше завершенных, если не переданы ?pinnedFirst=false и ?completedLast=false)
+// и пагинацию ?page=&pageSize= или курсором ?afterId=.
+// Ответ - {data, meta}; ?envelope=false возвращает просто массив.
+// С ?titlePrefix= вместо списка отдаются подсказки для автодополнения (см. autocompleteTasks).
+// Вместо описания отдается descriptionPreview, если не указан ?fullDescription=true.
 func GetTasks(c *gin.Context) {
-	var tasks []Task
-	db.Find(&tasks)
-	c.JSON(http.StatusOK, tasks)
+	filter, err := parseTaskFilter(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if prefix, ok := c.GetQuery("titlePrefix"); ok {
+		autocompleteTasks(c, filter, prefix)
+		return
+	}
+	tasks, meta, ok := findTaskPage(c, filter)
+	if !ok {
+		return
+	}
+	writeListResponse(c, tasks, meta)
+}
+
+// findTaskPage - Общий путь выборки списка: фильтр, сортировка ?sort= и пагинация из строки запроса
+// При ошибке ответ уже отправлен и возвращается false.
+func findTaskPage(c *gin.Context, filter TaskFilter) ([]Task, ListMeta, bool) {
+	query, order, ok := taskListQuery(c, filter)
+	if !ok {
+		return nil, ListMeta{}, false
+	}
+	return paginate(c, query, order)
+}
+
+// taskListQuery - Запрос списка по фильтру и порядок из ?sort= (без пагинации)
+// При ошибке ответ уже отправлен и возвращается false.
+func taskListQuery(c *gin.Context, filter TaskFilter) (*gorm.DB, listOrder, bool) {
+	sort, err := parseSort(c.Query("sort"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, listOrder{}, false
+	}
+	if err := resolveAssignee(c, &filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, listOrder{}, false
+	}
+	order := listOrder{SQL: sort}
+	// Нечеткий поиск без явной сортировки - самые похожие названия первыми
+	if sort == "" && filter.Fuzzy && filter.Search != "" && c.Query("afterId") == "" {
+		order = listOrder{SQL: "similarity(title, ?) DESC", Vars: []interface{}{filter.Search}}
+	}
+	// Курсор ?afterId= работает только при порядке по id, поэтому с ним завершенные не переносятся
+	if completedLast(c) && c.Query("afterId") == "" {
+		order = order.withPrimary("is_completed ASC")
+	}
+	if pinnedFirst(c) && c.Query("afterId") == "" {
+		order = order.withPrimary("pinned DESC")
+	}
+	return applyTaskFilter(txFromContext(c).Model(&Task{}), filter), order, true
+}
+
+// GetInbox - Неразобранные задачи текущего пользователя (GET /tasks/inbox)
+// То же, что ?filter=inbox, но всегда в пределах задач пользователя; total в meta - размер входящих.
+// Остальные фильтры, сортировка и пагинация - как у GET /tasks.
+func GetInbox(c *gin.Context) {
+	filter, err := parseTaskFilter(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.Preset != "" && filter.Preset != "inbox" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "filter cannot be combined with the inbox"})
+		return
+	}
+	filter.Preset = "inbox"
+	query, order, ok := taskListQuery(c, filter)
+	if !ok {
+		return
+	}
+	tasks, meta, ok := paginate(c, scopeToUser(c, query), order)
+	if !ok {
+		return
+	}
+	writeListResponse(c, tasks, meta)
 }
 
 // GetTaskByID - Получить задачу по ID
 func GetTaskByID(c *gin.Context) {
-	id := c.Param("id")
-	var task Task
-	if result := db.First(&task, id); result.Error != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	id, ok := parseID(c)
+	if !ok {
 		return
 	}
+	task, ok := loadTask(c, id)
+	if !ok {
+		return
+	}
+	enrichTask(c, &task)
 	c.JSON(http.StatusOK, task)
 }
 
+// loadTask - Задача по id через кэш задач (если он включен); заголовок X-Cache: HIT/MISS
+// При ошибке ответ уже отправлен и возвращается false.
+func loadTask(c *gin.Context, id uint) (Task, bool) {
+	if !taskCache.enabled() {
+		var task Task
+		if result := txFromContext(c).First(&task, id); result.Error != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return task, false
+		}
+		return task, true
+	}
+
+	task, generation, hit := taskCache.get(id)
+	if hit {
+		c.Header("X-Cache", "HIT")
+		return task, true
+	}
+	c.Header("X-Cache", "MISS")
+	if result := txFromContext(c).First(&task, id); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return task, false
+	}
+	taskCache.put(task, generation)
+	return task, true
+}
+
+// GetTasksByIDs - Получить несколько задач по списку ID за один запрос
+// Отсутствующие ID не попадают в tasks и перечисляются в missing.
+func GetTasksByIDs(c *gin.Context) {
+	var requestBody struct {
+		IDs []uint `json:"ids" binding:"required,min=1,max=100"`
+	}
+	if err := bindJSON(c, &requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ids must be a list of 1 to 100 task ids"})
+		return
+	}
+
+	var tasks []Task
+	if result := txFromContext(c).Where("id IN ?", requestBody.IDs).Order("id").Find(&tasks); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load tasks"})
+		return
+	}
+	enrichTasks(c, tasks)
+
+	found := make(map[uint]bool, len(tasks))
+	for _, task := range tasks {
+		found[task.ID] = true
+	}
+	missing := []uint{}
+	for _, id := range requestBody.IDs {
+		if !found[id] {
+			missing = append(missing, id)
+			found[id] = true // Повторяющиеся ID не дублируем в missing
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tasks": tasks, "missing": missing})
+}
+
 // UpdateTask - Обновить существующую задачу
 func UpdateTask(c *gin.Context) {
-	id := c.Param("id")
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	tx := txFromContext(c)
+	var task Task
+	if result := tx.First(&task, id); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	createdBy := task.CreatedBy
+	if err := bindTaskJSON(c, &task); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	task.CreatedBy = createdBy // Автор задачи не меняется
+	if err := validateTask(tx, &task); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if result := tx.Save(&task); result.Error != nil {
+		if isDuplicateTitle(result.Error) {
+			respondDuplicateTitle(c)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task"})
+		return
+	}
+	enrichTask(c, &task)
+	c.JSON(http.StatusOK, task)
+}
+
+// patchTaskField - Указатель на поле задачи по имени JSON-ключа и имя колонки в БД
+// Поля, которых здесь нет (id, createdAt, updatedAt), через PATCH менять нельзя.
+func patchTaskField(task *Task, key string) (interface{}, string, bool) {
+	switch key {
+	case "title":
+		return &task.Title, "title", true
+	case "description":
+		return &task.Description, "description", true
+	case "priority":
+		return &task.Priority, "priority", true
+	case "dueDate":
+		return &task.DueDate, "due_date", true
+	case "tags":
+		return &task.Tags, "tags", true
+	case "flag":
+		return &task.Flag, "flag", true
+	case "isCompleted":
+		return &task.IsCompleted, "is_completed", true
+	case "status":
+		return &task.Status, "status", true
+	case "parentId":
+		return &task.ParentID, "parent_id", true
+	case "assigneeId":
+		return &task.AssigneeID, "assignee_id", true
+	}
+	return nil, "", false
+}
+
+// nullablePatchFields - Поля, которые можно очистить явным null
+var nullablePatchFields = map[string]bool{"dueDate": true, "parentId": true, "assigneeId": true}
+
+// applyTaskPatch - Применить изменения из JSON-объекта к задаче
+// Возвращает список измененных колонок для Select(...).Updates(...).
+// now - текущее время в часовом поясе запроса, от него считается dueDate в свободной форме.
+func applyTaskPatch(task *Task, patch map[string]json.RawMessage, now time.Time) ([]string, error) {
+	columns := make([]string, 0, len(patch)+2)
+	for key, value := range patch {
+		field, column, ok := patchTaskField(task, key)
+		if !ok {
+			return nil, fmt.Errorf("field %q cannot be patched", key)
+		}
+		if key == "dueDate" {
+			normalized, err := normalizeDueDate(value, now)
+			if err != nil {
+				return nil, err
+			}
+			value = normalized
+		}
+		if string(value) == "null" && !nullablePatchFields[key] {
+			return nil, fmt.Errorf("field %q cannot be null", key)
+		}
+		if err := json.Unmarshal(value, field); err != nil {
+			return nil, fmt.Errorf("invalid value for %q: %v", key, err)
+		}
+		columns = append(columns, column)
+	}
+	// status и isCompleted всегда сохраняются вместе (см. reconcileStatus)
+	_, hasStatus := patch["status"]
+	_, hasCompleted := patch["isCompleted"]
+	if hasStatus && !hasCompleted {
+		columns = append(columns, "is_completed")
+	} else if hasCompleted && !hasStatus {
+		columns = append(columns, "status")
+	}
+	if hasStatus || hasCompleted {
+		columns = append(columns, "completed_at")
+	}
+	return columns, nil
+}
+
+// PatchTask - Частично обновить задачу
+// Отсутствующее поле не меняется, поле со значением null очищается (например, dueDate).
+func PatchTask(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	tx := txFromContext(c)
 	var task Task
-	if result := db.First(&task, id); result.Error != nil {
+	if result := tx.First(&task, id); result.Error != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 		return
 	}
 
-	if err := c.ShouldBindJSON(&task); err != nil {
+	var patch map[string]json.RawMessage
+	if err := json.NewDecoder(c.Request.Body).Decode(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request body must be a JSON object"})
+		return
+	}
+
+	now, err := requestNow(c)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	db.Save(&task)
+	columns, err := applyTaskPatch(&task, patch, now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(columns) == 0 {
+		enrichTask(c, &task)
+		c.JSON(http.StatusOK, task)
+		return
+	}
+
+	if task.Title == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Title cannot be empty"})
+		return
+	}
+	if err := validateTask(tx, &task); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	columns = append(columns, "updated_at", "last_activity_at")
+	if result := tx.Model(&task).Select(columns).Updates(&task); result.Error != nil {
+		if isDuplicateTitle(result.Error) {
+			respondDuplicateTitle(c)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task"})
+		return
+	}
+	enrichTask(c, &task)
 	c.JSON(http.StatusOK, task)
 }
 
+// BulkUpdateTasks - Применить одни и те же изменения к нескольким задачам
+// Тело: {"ids": [1, 2, 3], "changes": {"priority": "high"}}. По умолчанию все в одной транзакции
+// (все или ничего); ?mode=partial обновляет каждую задачу отдельно и отвечает 207 с результатом по каждой.
+func BulkUpdateTasks(c *gin.Context) {
+	mode, ok := batchMode(c, batchAtomic)
+	if !ok {
+		return
+	}
+	var requestBody struct {
+		IDs     []uint                     `json:"ids" binding:"required,min=1,max=1000"`
+		Changes map[string]json.RawMessage `json:"changes" binding:"required"`
+	}
+	if err := bindJSON(c, &requestBody); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	ids := requestBody.IDs
+	if mode == batchPartial {
+		updateTasksPartially(c, ids, requestBody.Changes)
+		return
+	}
+	updateTasksWhere(c, requestBody.Changes, func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(&Task{}).Where("id IN ?", ids)
+	})
+}
+
+// Режимы пакетных операций (?mode=)
+const (
+	batchAtomic  = "atomic"  // Все или ничего
+	batchPartial = "partial" // Каждый элемент отдельно, ответ 207 Multi-Status
+)
+
+// batchMode - Режим пакетной операции из ?mode= (def, если параметр не задан)
+// При недопустимом значении ответ уже отправлен и возвращается false.
+func batchMode(c *gin.Context, def string) (string, bool) {
+	switch mode := c.Query("mode"); mode {
+	case "":
+		return def, true
+	case batchAtomic, batchPartial:
+		return mode, true
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "mode must be atomic or partial"})
+	return "", false
+}
+
+// BulkItemResult - Результат операции над одной задачей в режиме ?mode=partial
+type BulkItemResult struct {
+	ID     uint              `json:"id"`
+	Status int               `json:"status"` // HTTP-статус, который получил бы запрос к одной этой задаче
+	Error  string            `json:"error,omitempty"`
+	Code   string            `json:"code,omitempty"`
+	Errors map[string]string `json:"errors,omitempty"` // Ошибки проверки по полям
+}
+
+// updateTasksPartially - Применить изменения к каждой задаче по отдельности, как PATCH /tasks/:id
+// Каждая задача пишется в своей точке сохранения: ошибка откатывает только ее.
+// Ответ 207 Multi-Status: {"updated": N, "failed": M, "results": [...]} в порядке ids.
+func updateTasksPartially(c *gin.Context, ids []uint, patch map[string]json.RawMessage) {
+	if len(patch) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "changes must not be empty"})
+		return
+	}
+	if _, ok := patch["parentId"]; ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "parentId cannot be changed in bulk; use POST /tasks/:id/move"})
+		return
+	}
+	now, err := requestNow(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx := txFromContext(c)
+	results := make([]BulkItemResult, 0, len(ids))
+	seen := make(map[uint]bool, len(ids))
+	updated := 0
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		result := BulkItemResult{ID: id, Status: http.StatusOK}
+		err := tx.Transaction(func(item *gorm.DB) error {
+			var task Task
+			if err := item.First(&task, id).Error; err != nil {
+				result.Status, result.Error = http.StatusNotFound, "Task not found"
+				return err
+			}
+			columns, err := applyTaskPatch(&task, patch, now)
+			if err != nil {
+				result.Status, result.Error = http.StatusBadRequest, err.Error()
+				return err
+			}
+			if task.Title == "" {
+				result.Status, result.Error = http.StatusBadRequest, "Title cannot be empty"
+				return errors.New(result.Error)
+			}
+			if err := validateTask(item, &task); err != nil {
+				result.Status, result.Error, result.Code = http.StatusBadRequest, "Validation failed", "validation_failed"
+				result.Errors = validationErrors(err)
+				return err
+			}
+			columns = append(columns, "updated_at", "last_activity_at")
+			if err := item.Model(&task).Select(columns).Updates(&task).Error; err != nil {
+				if isDuplicateTitle(err) {
+					result.Status, result.Error, result.Code = http.StatusConflict, "An active task with this title already exists", "duplicate_title"
+				} else {
+					result.Status, result.Error = http.StatusInternalServerError, "Failed to update task"
+				}
+				return err
+			}
+			return nil
+		})
+		if err == nil {
+			updated++
+		} else if result.Status == http.StatusOK {
+			result.Status, result.Error = http.StatusInternalServerError, "Failed to update task"
+		}
+		results = append(results, result)
+	}
+
+	c.JSON(http.StatusMultiStatus, gin.H{"updated": updated, "failed": len(results) - updated, "results": results})
+}
+
+// UpdateTasksByFilter - Применить изменения ко всем задачам, подходящим под фильтр (PATCH /tasks?<фильтры>&confirm=true)
+// Тело - объект изменений, как у PATCH /tasks/:id. Без confirm=true и без единого условия фильтра
+// запрос отклоняется: случайный PATCH /tasks не должен переписать всю базу.
+func UpdateTasksByFilter(c *gin.Context) {
+	values := c.Request.URL.Query()
+	if values.Get("confirm") != "true" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "updating tasks by filter requires confirm=true", "code": "confirmation_required"})
+		return
+	}
+	values.Del("confirm")
+	filter, err := parseTaskFilter(values)
+	if err == nil {
+		err = resolveAssignee(c, &filter)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.isEmpty() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "at least one filter is required; use PATCH /tasks/bulk with explicit ids to update specific tasks", "code": "unbounded_update"})
+		return
+	}
+
+	var changes map[string]json.RawMessage
+	if err := json.NewDecoder(c.Request.Body).Decode(&changes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Request body must be a JSON object"})
+		return
+	}
+	// Условия фильтра идут подзапросом по id: пресеты и поиск рассчитаны на SELECT,
+	// а UPDATE получает только простое id IN (...)
+	updateTasksWhere(c, changes, func(tx *gorm.DB) *gorm.DB {
+		matching := applyTaskFilter(tx.Model(&Task{}).Select("id"), filter)
+		return tx.Model(&Task{}).Where("id IN (?)", matching)
+	})
+}
+
+// maxBulkUpdate - Сколько задач можно изменить одним массовым запросом
+// Id обновляемых строк передаются параметрами, а у Postgres лимит 65535 параметров на запрос.
+const maxBulkUpdate = 10000
+
+var errTooManyTasks = errors.New("too many tasks")
+
+// updateTasksWhere - Общая часть массового обновления: проверить изменения и применить их
+// к задачам из scope в одной транзакции; отвечает {"updated": N} или ошибкой.
+func updateTasksWhere(c *gin.Context, patch map[string]json.RawMessage, scope func(tx *gorm.DB) *gorm.DB) {
+	if len(patch) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "changes must not be empty"})
+		return
+	}
+	// Смена родителя требует проверки на циклы для каждой задачи отдельно
+	if _, ok := patch["parentId"]; ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "parentId cannot be changed in bulk; use POST /tasks/:id/move"})
+		return
+	}
+
+	var changes Task
+	now, err := requestNow(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	columns, err := applyTaskPatch(&changes, patch, now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, ok := patch["title"]; ok && changes.Title == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Title cannot be empty"})
+		return
+	}
+	if err := validateTask(txFromContext(c), &changes); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	// completed_at у changes - время сейчас для любой задачи; у уже завершенных его менять нельзя,
+	// поэтому оно проставляется отдельными запросами ниже
+	columns = slices.DeleteFunc(columns, func(column string) bool { return column == "completed_at" })
+	columns = append(columns, "updated_at", "last_activity_at")
+	_, statusChanged := patch["status"]
+	_, completedChanged := patch["isCompleted"]
+
+	var updated int64
+	var ids []uint
+	err = txFromContext(c).Transaction(func(tx *gorm.DB) error {
+		if statusChanged || completedChanged {
+			var blocked int64
+			if err := scope(tx).
+				Where("status NOT IN ?", statusesAllowedInto(changes.Status)).
+				Count(&blocked).Error; err != nil {
+				return err
+			}
+			if blocked > 0 {
+				return errInvalidTransition
+			}
+		}
+		// Завершение меняет выборку (например, completed=false), поэтому время завершения
+		// проставляется по id обновленных строк, а не повторным применением фильтра
+		if err := scope(tx).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if len(ids) > maxBulkUpdate {
+			return errTooManyTasks
+		}
+		result := tx.Model(&Task{}).Where("id IN ?", ids).Select(columns).Updates(&changes)
+		updated = result.RowsAffected
+		if result.Error != nil || !(statusChanged || completedChanged) {
+			return result.Error
+		}
+		// Время завершения ставится только задачам, которые еще не были завершены,
+		// и сбрасывается у открытых заново
+		if err := tx.Model(&Task{}).
+			Where("id IN ? AND is_completed AND completed_at IS NULL", ids).
+			UpdateColumn("completed_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Model(&Task{}).
+			Where("id IN ? AND NOT is_completed AND completed_at IS NOT NULL", ids).
+			UpdateColumn("completed_at", nil).Error
+	})
+	if isDuplicateTitle(err) {
+		respondDuplicateTitle(c)
+		return
+	}
+	if errors.Is(err, errInvalidTransition) {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("some tasks cannot change status to %s", changes.Status)})
+		return
+	}
+	if errors.Is(err, errTooManyTasks) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("filter matches more than %d tasks; narrow it down", maxBulkUpdate), "code": "too_many_tasks"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tasks"})
+		return
+	}
+	if len(ids) > 0 {
+		invalidateTaskCachesOnCommit(c.Request.Context(), ids...) // completed_at проставлен UpdateColumn без хуков
+	}
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
+
 // DeleteTask - Удалить задачу
+// По умолчанию задача удаляется мягко (в корзину) и в ответе приходит undoToken для
+// POST /tasks/undo; ?force=true удаляет окончательно, в том числе задачу, которая уже лежит в корзине.
 func DeleteTask(c *gin.Context) {
-	id := c.Param("id")
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	force := c.Query("force") == "true"
+
+	query := txFromContext(c)
+	if force {
+		query = query.Unscoped().Session(&gorm.Session{})
+	}
 	var task Task
-	if result := db.First(&task, id); result.Error != nil {
+	if result := query.First(&task, id); result.Error != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
 		return
 	}
-	db.Delete(&task)
-	c.JSON(http.StatusNoContent, nil)
+	if result := query.Delete(&task); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete task"})
+		return
+	}
+	if force || undoTTL <= 0 {
+		c.JSON(http.StatusNoContent, nil)
+		return
+	}
+	token, err := issueUndoToken(query, task.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete task"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"undoToken": token.Token, "expiresAt": token.ExpiresAt})
 }
 
-// --- Интеграция с ИИ-агентом (Заглушка) ---
-// AIProcessQuery - Конечная точка для обработки запросов к ИИ-агенту
-func AIProcessQuery(c *gin.Context) {
-	var requestBody struct {
-		Query string `json:"query" binding:"required"`
+// enrichTasks - Заполнить вычисляемые поля задач (прогресс подзадач, затраченное время,
+// чек-лист, ссылки, признак needsReview, название приоритета на языке запроса, расширения ?expand=)
+func enrichTasks(c *gin.Context, tasks []Task) {
+	tx := txFromContext(c)
+	attachProgress(tx, tasks)
+	attachTimeSpent(tx, tasks)
+	if featureEnabled("checklists") {
+		attachChecklists(tx, tasks)
 	}
-	if err := c.ShouldBindJSON(&requestBody); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Query is required"})
+	attachLinks(tx, tasks)
+	markNeedsReview(tasks)
+	localizePriorities(c, tasks)
+	attachExpansions(c, tasks)
+}
+
+// enrichTask - Заполнить вычисляемые поля одной задачи
+func enrichTask(c *gin.Context, task *Task) {
+	tasks := []Task{*task}
+	enrichTasks(c, tasks)
+	*task = tasks[0]
+}
+
+// DeleteCompletedTasks - Удалить все завершенные задачи, созданные текущим пользователем, одним запросом
+// Требует ?confirm=true; ?hard=true удаляет окончательно, иначе задачи попадают в корзину.
+// ?mode=partial удаляет каждую задачу отдельно и отвечает 207 с результатом по каждой.
+func DeleteCompletedTasks(c *gin.Context) {
+	if c.Query("confirm") != "true" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This deletes all completed tasks; repeat the request with ?confirm=true"})
+		return
+	}
+	mode, ok := batchMode(c, batchAtomic)
+	if !ok {
 		return
 	}
 
-	userQuery := requestBody.Query
-	log.Printf("Received AI query: \"%s\"", userQuery)
+	hard := c.Query("hard") == "true"
+	query := txFromContext(c)
+	if hard {
+		query = query.Unscoped()
+	}
+	if mode == batchPartial {
+		deleteTasksPartially(c, scopeToOwner(c, query.Model(&Task{})).Where("is_completed = ?", true), hard)
+		return
+	}
+	result := scopeToOwner(c, query).Where("is_completed = ?", true).Delete(&Task{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete completed tasks"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deleted": result.RowsAffected})
+}
 
-	// --- Здесь будет ваша основная логика ИИ-агента ---
-	// 1. Отправка запроса в LLM API (OpenAI, Google Gemini и т.д.)
-	// 2. Интерпретация ответа LLM для получения критериев фильтрации
-	// 3. Фильтрация задач из базы данных на основе полученных критериев
+// deleteTasksPartially - Удалить задачи из matching по одной, каждую в своей точке сохранения
+// Ответ 207 Multi-Status: {"deleted": N, "failed": M, "results": [...]} в порядке id.
+func deleteTasksPartially(c *gin.Context, matching *gorm.DB, hard bool) {
+	var ids []uint
+	if err := matching.Order("id").Pluck("id", &ids).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete completed tasks"})
+		return
+	}
+	if len(ids) > maxBulkUpdate {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("more than %d tasks match; use the default mode", maxBulkUpdate), "code": "too_many_tasks"})
+		return
+	}
+	scoped := func(q *gorm.DB) *gorm.DB {
+		if hard {
+			return q.Unscoped()
+		}
+		return q
+	}
 
-	var filteredTasks []Task
-	// Просто пример фильтрации по ключевым словам для демонстрации
-	if userQuery == "покажи срочные" {
-		db.Where("priority = ?", "высокий").Find(&filteredTasks)
-	} else if userQuery == "покажи завершенные" {
-		db.Where("is_completed = ?", true).Find(&filteredTasks)
-	} else if userQuery == "покажи незавершенные" {
-		db.Where("is_completed = ?", false).Find(&filteredTasks)
-	} else {
-		db.Find(&filteredTasks)
-		log.Println("AI could not provide specific filters, returning all tasks (or implement LLM clarification).")
+	tx := txFromContext(c)
+	results := make([]BulkItemResult, 0, len(ids))
+	deleted := 0
+	for _, id := range ids {
+		result := BulkItemResult{ID: id, Status: http.StatusOK}
+		err := tx.Transaction(func(item *gorm.DB) error {
+			var task Task
+			if err := scoped(item).First(&task, id).Error; err != nil {
+				result.Status, result.Error = http.StatusNotFound, "Task not found"
+				return err
+			}
+			return scoped(item).Delete(&task).Error
+		})
+		if err == nil {
+			deleted++
+		} else if result.Status == http.StatusOK {
+			result.Status, result.Error = http.StatusInternalServerError, "Failed to delete task"
+		}
+		results = append(results, result)
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":       fmt.Sprintf("Processing AI query: '%s'", userQuery),
-		"filteredTasks": filteredTasks,
-		"note":          "AI logic is currently a placeholder. Implement LLM API calls and robust filtering here.",
-	})
+	c.JSON(http.StatusMultiStatus, gin.H{"deleted": deleted, "failed": len(results) - deleted, "results": results})
 }
 
 // --- Главная функция ---
 func main() {
-	initDB() // Инициализация базы данных при запуске приложения
+	// Управление миграциями: ./my-task-app migrate up|down [N]|status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		connectDB()
+		runMigrateCommand(os.Args[2:])
+		return
+	}
+
+	initDB()            // Инициализация базы данных при запуске приложения
+	initFeatures()      // Флаги необязательных функций (FEATURE_*)
+	initAI()            // Выбор LLM-провайдера для ИИ-агента
+	useJSONFieldNames() // Поля в ошибках валидации называются как в JSON
+
+	go runDeletedTasksJanitor() // Фоновая очистка давно удаленных задач
+	go runReminderScheduler()   // Напоминания о сроках задач
+	go runWebhookDispatcher()   // Доставка событий задач во внешние сервисы
+	if autoArchiveEnabled() {
+		go runAutoArchiver() // Архивация давно завершенных задач
+	}
 
-	router := gin.Default()
+	router := setupRouter()
+	log.Fatal(router.Run(":8080")) // Запуск сервера на порту 8080
+}
+
+// setupRouter - Регистрация всех маршрутов API
+func setupRouter() *gin.Engine {
+	router := gin.New()
+	router.Use(requestID(), requestLogger(), bodyLogger(), serverTiming(), prettyJSON(), timeFormat(), recovery(), readOnlyGuard(), requestTransaction())
+
+	// Прокси, которым разрешено передавать адрес клиента в X-Forwarded-For (TRUSTED_PROXIES)
+	if err := router.SetTrustedProxies(trustedProxies()); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	// Ping-маршрут (для проверки доступности сервера)
 	router.GET("/ping", func(c *gin.Context) {
@@ -170,18 +1123,162 @@ func main() {
 		})
 	})
 
+	// Готовность к приему трафика: БД доступна и схема актуальна
+	router.GET("/readyz", Readyz)
+
+	// Версия сборки и включенные функции
+	router.GET("/version", GetVersion)
+
+	// Настройки текущего пользователя (часовой пояс по умолчанию вместо ?tz=)
+	router.GET("/me/settings", GetMySettings)
+	router.PUT("/me/settings", UpdateMySettings)
+
+	// Вебхуки: подписки, журнал доставок и повторная отправка (только для администратора)
+	router.POST("/webhooks", adminOnly(), CreateWebhook)
+	router.GET("/webhooks", adminOnly(), GetWebhooks)
+	router.DELETE("/webhooks/:id", adminOnly(), DeleteWebhook)
+	router.GET("/webhooks/:id/deliveries", adminOnly(), GetWebhookDeliveries)
+	router.POST("/webhooks/deliveries/:id/replay", adminOnly(), ReplayWebhookDelivery)
+
+	// Режим только для чтения на время обслуживания (только для администратора)
+	router.GET(readOnlyPath, adminOnly(), GetReadOnly)
+	router.PUT(readOnlyPath, adminOnly(), SetReadOnly)
+
 	// Группировка маршрутов для API задач
 	tasksGroup := router.Group("/tasks")
 	{
 		tasksGroup.POST("/", CreateTask)
 		tasksGroup.GET("/", GetTasks)
 		tasksGroup.GET("/:id", GetTaskByID)
+		tasksGroup.POST("/query", GetTasksByIDs)
 		tasksGroup.PUT("/:id", UpdateTask)
+		tasksGroup.PATCH("/:id", PatchTask)
+		tasksGroup.PATCH("/bulk", BulkUpdateTasks)
+		// Перенос всех просроченных задач на новый срок
+		tasksGroup.POST("/reschedule-overdue", RescheduleOverdueTasks)
+		tasksGroup.PATCH("/", UpdateTasksByFilter)
 		tasksGroup.DELETE("/:id", DeleteTask)
+		// Отмена мягкого удаления по undoToken из ответа DELETE (в течение UNDO_TTL)
+		tasksGroup.POST("/undo", UndoDeleteTask)
+		tasksGroup.DELETE("/completed", DeleteCompletedTasks)
+
+		// Полнотекстовый поиск (?q=)
+		tasksGroup.GET("/search", requireFeature("search"), SearchTasks)
+
+		// Идемпотентное создание/обновление по внешнему ID
+		tasksGroup.PUT("/external/:externalId", UpsertTaskByExternalID)
+		// Массовый импорт (?upsert=true - обновление по externalId)
+		tasksGroup.POST("/import", ImportTasks)
+
+		// Календарные представления (?tz= или пояс из настроек пользователя)
+		tasksGroup.GET("/today", GetTodayTasks)
+		tasksGroup.GET("/digest", GetDigest)
+		// Число просроченных задач по приоритетам и по дням просрочки
+		tasksGroup.GET("/overdue/summary", GetOverdueSummary)
+
+		// Входящие: неразобранные задачи без срока, приоритета и родителя
+		tasksGroup.GET("/inbox", GetInbox)
+
+		// Задачи верхнего уровня с вложенными подзадачами (?depth=)
+		tasksGroup.GET("/tree", GetTaskTree)
+
+		// Перенос подзадачи к другому родителю (или на верхний уровень) и перетаскивание на место
+		tasksGroup.POST("/:id/move", MoveTask)
+		tasksGroup.POST("/:id/move-to", MoveTaskTo)
+
+		// Канбан-доска по статусам и перестановка карточек
+		tasksGroup.GET("/board", requireFeature("board"), GetBoard)
+		tasksGroup.POST("/:id/reorder", ReorderTask)
+
+		// История изменений и сравнение версий
+		tasksGroup.GET("/:id/history", GetTaskHistory)
+		tasksGroup.GET("/:id/history/:version/diff", GetTaskHistoryDiff)
+
+		// Экспорт: одна задача в Markdown, список задач пользователя в CSV, JSON или ICS
+		tasksGroup.GET("/:id/export", requireFeature("export"), ExportTask)
+		tasksGroup.GET("/export", requireFeature("export"), ExportTasks)
+
+		// Переключение завершенности одним атомарным UPDATE
+		tasksGroup.POST("/:id/toggle", ToggleTask)
+
+		// Архив
+		tasksGroup.POST("/:id/archive", ArchiveTask)
+		tasksGroup.POST("/:id/unarchive", UnarchiveTask)
+
+		// Закрепление вверху списков
+		tasksGroup.POST("/:id/pin", PinTask)
+		tasksGroup.POST("/:id/unpin", UnpinTask)
+
+		// Копия задачи (?withSubtasks=true - вместе с подзадачами)
+		tasksGroup.POST("/:id/duplicate", DuplicateTask)
+
+		// Слияние дубликата (тело {"sourceId": N}) в задачу :id
+		tasksGroup.POST("/:id/merge", MergeTask)
+
+		// Чек-лист внутри задачи
+		tasksGroup.POST("/:id/checklist", requireFeature("checklists"), AddChecklistItem)
+		tasksGroup.POST("/:id/checklist/reorder", requireFeature("checklists"), ReorderChecklist)
+		tasksGroup.PATCH("/:id/checklist/:itemId", requireFeature("checklists"), UpdateChecklistItem)
+		tasksGroup.DELETE("/:id/checklist/:itemId", requireFeature("checklists"), DeleteChecklistItem)
+
+		// Ссылки на внешние ресурсы
+		tasksGroup.POST("/:id/links", AddTaskLink)
+		tasksGroup.DELETE("/:id/links/:linkId", DeleteTaskLink)
+
+		// Зависимости и рекомендуемый порядок выполнения
+		tasksGroup.GET("/:id/dependencies", GetDependencies)
+		tasksGroup.POST("/:id/dependencies", AddDependency)
+		tasksGroup.DELETE("/:id/dependencies/:dependsOnId", DeleteDependency)
+		tasksGroup.GET("/order", GetCompletionOrder)
+		tasksGroup.GET("/:id/related", GetRelatedTasks)
+
+		// Напоминания о сроке
+		tasksGroup.GET("/:id/reminders", GetReminders)
+		tasksGroup.POST("/:id/reminders", AddReminder)
+		tasksGroup.DELETE("/:id/reminders", ClearReminders)
+		tasksGroup.DELETE("/:id/reminders/:reminderId", DeleteReminder)
+
+		// Учет времени
+		tasksGroup.POST("/:id/time", AddTimeEntry)
+		tasksGroup.GET("/:id/time", GetTimeEntries)
+		tasksGroup.GET("/stats/time", GetTimeStats)
+
+		// Сводная статистика (кэшируется)
+		tasksGroup.GET("/stats", GetTaskStats)
+		// Созданные и завершенные задачи по дням, неделям или месяцам
+		tasksGroup.GET("/stats/timeseries", GetStatsTimeseries)
+		// Счетчики для нескольких именованных фильтров одним запросом (для дашбордов)
+		tasksGroup.POST("/stats/batch", GetBatchStats)
+
+		// Окончательное удаление задач из корзины (только для администратора)
+		tasksGroup.POST("/purge-deleted", adminOnly(), PurgeDeletedTasks)
+		// SQL и план запроса для фильтров списка (только при FEATURE_DEBUG=true)
+		tasksGroup.GET("/explain", requireFeature("debug"), ExplainTasks)
+		// Внеочередная автоархивация завершенных задач (только для администратора)
+		tasksGroup.POST("/auto-archive", adminOnly(), AutoArchiveTasks)
 	}
 
+	// Сохраненные представления (именованные фильтры)
+	viewsGroup := router.Group("/views")
+	{
+		viewsGroup.POST("/", CreateView)
+		viewsGroup.GET("/", GetViews)
+		viewsGroup.GET("/:id", GetViewByID)
+		viewsGroup.PUT("/:id", UpdateView)
+		viewsGroup.DELETE("/:id", DeleteView)
+		viewsGroup.GET("/:id/tasks", GetViewTasks)
+	}
+
+	// Теги: список (с поддержкой ?prefix= для автодополнения) и цвета
+	router.GET("/tags", GetTags)
+	// Приоритеты с названиями и цветами для отображения
+	router.GET("/priorities", GetPriorities)
+	router.PUT("/tags/:name", SetTagColor)
+	router.DELETE("/tags/:name", DeleteTagColor)
+
 	// Маршрут для ИИ-агента
-	router.POST("/ai/query", AIProcessQuery)
+	router.POST("/ai/query", requireFeature("ai"), AIProcessQuery)
+	router.GET("/ai/metrics", adminOnly(), GetAIMetrics)
 
-	log.Fatal(router.Run(":8080")) // Запуск сервера на порту 8080
+	return router
 }
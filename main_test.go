@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// --- Общие помощники тестов ---
+
+// Тесты с базой данных используют отдельную базу PostgreSQL из TEST_DATABASE_URL
+// (ее таблицы очищаются) и пропускаются, если переменная не задана.
+
+var (
+	testDBOnce sync.Once
+	testDBErr  error
+	testRouter *gin.Engine
+)
+
+// openTestDB - Подключение к тестовой базе без логирования SQL
+func openTestDB(dsn string) (*gorm.DB, error) {
+	return gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+}
+
+// setupTestDB - Направить глобальный db в тестовую базу с актуальной схемой и пустыми задачами
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	testDBOnce.Do(func() {
+		gin.SetMode(gin.TestMode)
+		if db, testDBErr = openTestDB(dsn); testDBErr != nil {
+			return
+		}
+		testDBErr = errors.Join(registerTimingCallbacks(db), runMigrations())
+		testRouter = setupRouter()
+	})
+	if testDBErr != nil {
+		t.Fatalf("prepare test database: %v", testDBErr)
+	}
+	if err := db.Exec("TRUNCATE tasks, undo_tokens RESTART IDENTITY CASCADE").Error; err != nil {
+		t.Fatalf("clean test database: %v", err)
+	}
+	taskCache = newTaskLRU(0, time.Minute)
+	taskStatsCache.invalidate()
+}
+
+// dryRunDB - Подключение, которое только строит запросы (DryRun): SQL и параметры берутся из Statement
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	conn, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{DryRun: true, DisableAutomaticPing: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open dry-run connection: %v", err)
+	}
+	return conn
+}
+
+// testSchemaDB - Подключение к тестовой базе в пустой схеме name (удаляется после теста)
+// public остается в search_path ради расширения pg_trgm.
+func testSchemaDB(t *testing.T, name string) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		query := u.Query()
+		query.Set("search_path", name+",public")
+		u.RawQuery = query.Encode()
+		dsn = u.String()
+	} else {
+		dsn += " search_path=" + name + ",public"
+	}
+	db.Exec("DROP SCHEMA IF EXISTS " + name + " CASCADE")
+	if err := db.Exec("CREATE SCHEMA " + name).Error; err != nil {
+		t.Fatalf("create schema %s: %v", name, err)
+	}
+	conn, err := openTestDB(dsn)
+	if err != nil {
+		t.Fatalf("connect to schema %s: %v", name, err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := conn.DB(); err == nil {
+			sqlDB.Close()
+		}
+		db.Exec("DROP SCHEMA IF EXISTS " + name + " CASCADE")
+	})
+	return conn
+}
+
+// createTestTask - Вставить задачу напрямую в базу
+func createTestTask(t *testing.T, task Task) Task {
+	t.Helper()
+	if err := db.Create(&task).Error; err != nil {
+		t.Fatalf("create task %q: %v", task.Title, err)
+	}
+	return task
+}
+
+// stringPtr - Указатель на строку (для CreatedBy, AssigneeID)
+func stringPtr(s string) *string {
+	return &s
+}
+
+// performRequest - Выполнить запрос к роутеру от имени userID (пустая строка - без заголовка пользователя)
+func performRequest(method, path, body, userID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if userID != "" {
+		req.Header.Set(userHeader, userID)
+	}
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	return w
+}
+
+// responseTaskIDs - Id задач из ответа списка ({"data": [...]}) в порядке ответа
+func responseTaskIDs(t *testing.T, w *httptest.ResponseRecorder) []uint {
+	t.Helper()
+	var body struct {
+		Data []Task `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode list response: %v; body: %s", err, w.Body.String())
+	}
+	ids := make([]uint, len(body.Data))
+	for i, task := range body.Data {
+		ids[i] = task.ID
+	}
+	return ids
+}
+
+// expectStatus - Проверить код ответа и показать тело при несовпадении
+func expectStatus(t *testing.T, w *httptest.ResponseRecorder, status int) {
+	t.Helper()
+	if w.Code != status {
+		t.Fatalf("status = %d, want %d; body: %s", w.Code, status, w.Body.String())
+	}
+}
+
+// TestDeleteCompletedTasksScopedToUser - DELETE /tasks/completed удаляет только завершенные задачи,
+// созданные текущим пользователем (не назначенные ему), в том числе при окончательном удалении
+func TestDeleteCompletedTasksScopedToUser(t *testing.T) {
+	setupTestDB(t)
+	ownDone := createTestTask(t, Task{Title: "A done", Status: StatusDone, IsCompleted: true, CreatedBy: stringPtr("alice")})
+	ownOpen := createTestTask(t, Task{Title: "A open", CreatedBy: stringPtr("alice")})
+	otherDone := createTestTask(t, Task{Title: "B done", Status: StatusDone, IsCompleted: true, CreatedBy: stringPtr("bob")})
+	assignedDone := createTestTask(t, Task{Title: "B for A done", Status: StatusDone, IsCompleted: true, CreatedBy: stringPtr("bob"), AssigneeID: stringPtr("alice")})
+
+	for _, path := range []string{"/tasks/completed?confirm=true", "/tasks/completed?confirm=true&hard=true"} {
+		w := performRequest(http.MethodDelete, path, "", "alice")
+		expectStatus(t, w, http.StatusOK)
+	}
+
+	var remaining []uint
+	if err := db.Unscoped().Model(&Task{}).Order("id").Pluck("id", &remaining).Error; err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint{ownOpen.ID, otherDone.ID, assignedDone.ID}; !slices.Equal(remaining, want) {
+		t.Errorf("remaining tasks = %v, want %v (task %d must be deleted)", remaining, want, ownDone.ID)
+	}
+}
+
+// TestTitleUniquePerUser - Одинаковые названия у разных пользователей допустимы, у одного - 409
+func TestTitleUniquePerUser(t *testing.T) {
+	setupTestDB(t)
+	body := `{"title": "Buy milk"}`
+	expectStatus(t, performRequest(http.MethodPost, "/tasks/", body, "alice"), http.StatusCreated)
+	expectStatus(t, performRequest(http.MethodPost, "/tasks/", body, "bob"), http.StatusCreated)
+
+	w := performRequest(http.MethodPost, "/tasks/", body, "alice")
+	expectStatus(t, w, http.StatusConflict)
+	if !strings.Contains(w.Body.String(), "duplicate_title") {
+		t.Errorf("conflict body = %s, want code duplicate_title", w.Body.String())
+	}
+}
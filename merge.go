@@ -0,0 +1,152 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// --- Слияние задач-дубликатов ---
+
+// mergeTags - Объединить теги двух задач без повторов (без учета регистра), порядок сохраняется
+func mergeTags(target, source string) string {
+	seen := make(map[string]bool)
+	var tags []string
+	for _, tag := range append(splitTags(target), splitTags(source)...) {
+		if key := strings.ToLower(tag); !seen[key] {
+			seen[key] = true
+			tags = append(tags, tag)
+		}
+	}
+	return strings.Join(tags, ", ")
+}
+
+// mergeInto - Перенести в target поля source (теги, описание, ближайший срок)
+func mergeInto(target *Task, source Task) {
+	target.Tags = mergeTags(target.Tags, source.Tags)
+	switch {
+	case target.Description == "":
+		target.Description = source.Description
+	case source.Description != "" && source.Description != target.Description:
+		target.Description += "\n\n" + source.Description
+	}
+	if source.DueDate != nil && (target.DueDate == nil || source.DueDate.Before(*target.DueDate)) {
+		target.DueDate = source.DueDate
+	}
+}
+
+// moveTaskRecords - Перенести связанные записи source в target
+// История изменений остается у source: это журнал именно той задачи.
+func moveTaskRecords(tx *gorm.DB, sourceID, targetID uint) error {
+	// Подзадачи и пункты чек-листа встают после собственных, сохраняя порядок между собой
+	base := nextPosition(tx, &targetID)
+	if err := siblings(tx, &sourceID).
+		UpdateColumns(map[string]interface{}{"parent_id": targetID, "position": gorm.Expr("position + ?", base)}).Error; err != nil {
+		return err
+	}
+	var maxItem *int
+	if err := tx.Model(&ChecklistItem{}).Where("task_id = ?", targetID).Select("MAX(position)").Scan(&maxItem).Error; err != nil {
+		return err
+	}
+	itemBase := 0
+	if maxItem != nil {
+		itemBase = *maxItem + 1
+	}
+	if err := tx.Model(&ChecklistItem{}).Where("task_id = ?", sourceID).
+		UpdateColumns(map[string]interface{}{"task_id": targetID, "position": gorm.Expr("position + ?", itemBase)}).Error; err != nil {
+		return err
+	}
+
+	// Ссылки, которые уже есть у target, не дублируются
+	if err := tx.Where("task_id = ? AND url IN (?)", sourceID,
+		tx.Model(&TaskLink{}).Select("url").Where("task_id = ?", targetID)).Delete(&TaskLink{}).Error; err != nil {
+		return err
+	}
+	for _, model := range []interface{}{&TaskLink{}, &TimeEntry{}, &Reminder{}} {
+		if err := tx.Model(model).Where("task_id = ?", sourceID).UpdateColumn("task_id", targetID).Error; err != nil {
+			return err
+		}
+	}
+
+	// Зависимости переходят на target; ребра между самими задачами и повторы отбрасываются
+	if err := tx.Exec(`INSERT INTO task_dependencies (task_id, depends_on_id, created_at)
+			SELECT ?, depends_on_id, created_at FROM task_dependencies WHERE task_id = ? AND depends_on_id <> ?
+			ON CONFLICT DO NOTHING`, targetID, sourceID, targetID).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec(`INSERT INTO task_dependencies (task_id, depends_on_id, created_at)
+			SELECT task_id, ?, created_at FROM task_dependencies WHERE depends_on_id = ? AND task_id <> ?
+			ON CONFLICT DO NOTHING`, targetID, sourceID, targetID).Error; err != nil {
+		return err
+	}
+	return tx.Where("task_id = ? OR depends_on_id = ?", sourceID, sourceID).Delete(&TaskDependency{}).Error
+}
+
+// MergeTask - Слить дубликат в задачу (POST /tasks/:id/merge)
+// Тело: {"sourceId": 7}. Теги объединяются, описания склеиваются, остается более ранний срок;
+// подзадачи, чек-лист, ссылки, учет времени, напоминания и зависимости переходят к задаче :id,
+// а source мягко удаляется (его можно восстановить из корзины). Все в одной транзакции.
+func MergeTask(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var requestBody struct {
+		SourceID uint `json:"sourceId" binding:"required"`
+	}
+	if err := bindJSON(c, &requestBody); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if requestBody.SourceID == id {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a task cannot be merged into itself"})
+		return
+	}
+
+	tx := txFromContext(c)
+	var target, source Task
+	if result := tx.First(&target, id); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+	if result := tx.First(&source, requestBody.SourceID); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Source task not found"})
+		return
+	}
+	// Иначе подзадачи source, среди которых есть target, окажутся под самим target
+	if isAncestorOrSelf(tx, source.ID, target.ID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "a task cannot be merged into its own subtask"})
+		return
+	}
+
+	mergeInto(&target, source)
+	if err := validateTask(tx, &target); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		if err := moveTaskRecords(tx, source.ID, target.ID); err != nil {
+			return err
+		}
+		if err := tx.Model(&target).
+			Select("tags", "description", "due_date", "updated_at", "last_activity_at").
+			Updates(&target).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&source).Error
+	})
+	if err != nil {
+		if isDuplicateTitle(err) {
+			c.JSON(http.StatusConflict, gin.H{"error": "a subtask of the source has the same title as a subtask of the target", "code": "duplicate_title"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge tasks"})
+		return
+	}
+	invalidateTaskCachesOnCommit(c.Request.Context()) // Подзадачи source перенесены по условию, без хуков
+	enrichTask(c, &target)
+	c.JSON(http.StatusOK, target)
+}
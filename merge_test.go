@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMergeInto(t *testing.T) {
+	early := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	late := early.AddDate(0, 0, 7)
+	target := Task{Tags: "работа, Срочно", Description: "Основная", DueDate: &late}
+	mergeInto(&target, Task{Tags: "срочно, дом", Description: "Дубликат", DueDate: &early})
+
+	if target.Tags != "работа, Срочно, дом" {
+		t.Errorf("tags = %q", target.Tags)
+	}
+	if target.Description != "Основная\n\nДубликат" {
+		t.Errorf("description = %q", target.Description)
+	}
+	if !target.DueDate.Equal(early) {
+		t.Errorf("due date = %v, want the earlier %v", target.DueDate, early)
+	}
+}
+
+// TestMergeTask - Подзадачи и учет времени дубликата переходят к задаче, дубликат уходит в корзину
+func TestMergeTask(t *testing.T) {
+	setupTestDB(t)
+	target := createTestTask(t, Task{Title: "Отчет"})
+	source := createTestTask(t, Task{Title: "Отчет (копия)", Tags: "квартал"})
+	child := createTestTask(t, Task{Title: "Собрать цифры", ParentID: &source.ID})
+	entry := TimeEntry{TaskID: source.ID, StartedAt: time.Now(), DurationSeconds: 600}
+	if err := db.Create(&entry).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	path := fmt.Sprintf("/tasks/%d/merge", target.ID)
+	expectStatus(t, performRequest(http.MethodPost, path, fmt.Sprintf(`{"sourceId": %d}`, target.ID), ""), http.StatusBadRequest)
+	expectStatus(t, performRequest(http.MethodPost, fmt.Sprintf("/tasks/%d/merge", child.ID), fmt.Sprintf(`{"sourceId": %d}`, source.ID), ""), http.StatusBadRequest)
+
+	w := performRequest(http.MethodPost, path, fmt.Sprintf(`{"sourceId": %d}`, source.ID), "")
+	expectStatus(t, w, http.StatusOK)
+
+	var moved Task
+	if err := db.First(&moved, child.ID).Error; err != nil || moved.ParentID == nil || *moved.ParentID != target.ID {
+		t.Errorf("subtask parent = %v (%v), want %d", moved.ParentID, err, target.ID)
+	}
+	if err := db.First(&entry, entry.ID).Error; err != nil || entry.TaskID != target.ID {
+		t.Errorf("time entry task = %d (%v), want %d", entry.TaskID, err, target.ID)
+	}
+	var merged Task
+	if err := db.First(&merged, target.ID).Error; err != nil || merged.Tags != "квартал" {
+		t.Errorf("target tags = %q (%v), want квартал", merged.Tags, err)
+	}
+	if err := db.First(&Task{}, source.ID).Error; err == nil {
+		t.Error("source is still active after the merge")
+	}
+	if err := db.Unscoped().First(&Task{}, source.ID).Error; err != nil {
+		t.Errorf("source is not in the trash: %v", err)
+	}
+}
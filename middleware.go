@@ -0,0 +1,306 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- Middleware ---
+
+// adminOnly - Пропускает только запросы с заголовком X-Admin-Token, совпадающим с ADMIN_TOKEN
+// Если ADMIN_TOKEN не задан, административные маршруты отключены.
+func adminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := os.Getenv("ADMIN_TOKEN")
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin endpoints are disabled"})
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader("X-Admin-Token")), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin token required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// Заголовок с идентификатором пользователя (USER_HEADER). Его выставляет
+// аутентифицирующий прокси перед приложением; самому клиенту доверять нельзя.
+var userHeader = "X-User-ID"
+
+// currentUserID - Идентификатор пользователя текущего запроса (nil - анонимный запрос)
+func currentUserID(c *gin.Context) *string {
+	id := strings.TrimSpace(c.GetHeader(userHeader))
+	if id == "" {
+		return nil
+	}
+	return &id
+}
+
+// trustedProxies - Список доверенных прокси (IP или CIDR через запятую) из TRUSTED_PROXIES
+// Пустой список означает, что X-Forwarded-For игнорируется и адресом клиента считается адрес соединения.
+func trustedProxies() []string {
+	var proxies []string
+	for _, part := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy := strings.TrimSpace(part); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// clientIP - Реальный адрес клиента с учетом доверенных прокси
+// Используется для логирования и должен использоваться для ограничения частоты запросов.
+func clientIP(c *gin.Context) string {
+	return c.ClientIP()
+}
+
+// Заголовок с идентификатором запроса (принимается от клиента или прокси, иначе генерируется)
+const requestIDHeader = "X-Request-ID"
+
+// requestID - Присвоить запросу идентификатор и вернуть его в заголовке ответа
+func requestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestIDHeader)
+		if id == "" || len(id) > 128 {
+			buf := make([]byte, 8)
+			rand.Read(buf)
+			id = hex.EncodeToString(buf)
+		}
+		c.Set("requestId", id)
+		c.Header(requestIDHeader, id)
+		// Контекст запроса тоже несет id, чтобы его видел логгер SQL-запросов
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), requestIDKey{}, id))
+		c.Next()
+	}
+}
+
+// requestIDKey - Ключ идентификатора запроса в context.Context
+type requestIDKey struct{}
+
+// requestIDFromContext - Идентификатор запроса из контекста (пустая строка вне запроса)
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// requestIDFrom - Идентификатор текущего запроса
+func requestIDFrom(c *gin.Context) string {
+	return c.GetString("requestId")
+}
+
+// requestLogger - Логирование запросов с реальным адресом клиента из clientIP
+func requestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.Request.URL.Path
+		c.Next()
+		log.Printf("%s %s | %d | %v | %s | %s", c.Request.Method, path, c.Writer.Status(), time.Since(start), clientIP(c), requestIDFrom(c))
+	}
+}
+
+// recovery - Перехват паники: стек уходит в лог вместе с идентификатором запроса,
+// клиент получает только {"error": {"code": "internal", "requestId": ...}}.
+// PANIC_STACK_TRACES=false отключает запись стека в лог.
+func recovery() gin.HandlerFunc {
+	logStack := getEnvBool("PANIC_STACK_TRACES", true)
+	return func(c *gin.Context) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+			if logStack {
+				log.Printf("panic in %s %s (request %s): %v\n%s", c.Request.Method, c.Request.URL.Path, requestIDFrom(c), rec, debug.Stack())
+			} else {
+				log.Printf("panic in %s %s (request %s): %v", c.Request.Method, c.Request.URL.Path, requestIDFrom(c), rec)
+			}
+			if c.Writer.Written() {
+				// Ответ уже частично отправлен, изменить статус нельзя
+				c.Abort()
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": gin.H{"code": "internal", "requestId": requestIDFrom(c)},
+			})
+		}()
+		c.Next()
+	}
+}
+
+// prettyWriter - Буфер ответа, который prettyJSON форматирует перед отправкой
+type prettyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *prettyWriter) Write(data []byte) (int, error) { return w.body.Write(data) }
+
+func (w *prettyWriter) WriteString(s string) (int, error) { return w.body.WriteString(s) }
+
+// prettyJSON - Форматирование JSON-ответов с отступами для ручной отладки через curl
+// Включается параметром ?pretty=true или для всех запросов при APP_ENV=dev (?pretty=false отключает).
+// Ответы других типов (CSV, Markdown) отдаются без изменений.
+func prettyJSON() gin.HandlerFunc {
+	devDefault := os.Getenv("APP_ENV") == "dev"
+	return func(c *gin.Context) {
+		pretty := devDefault
+		if raw := c.Query("pretty"); raw != "" {
+			pretty = raw == "true"
+		}
+		if !pretty {
+			c.Next()
+			return
+		}
+
+		writer := &prettyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		body := writer.body.Bytes()
+		if strings.HasPrefix(writer.Header().Get("Content-Type"), "application/json") {
+			var indented bytes.Buffer
+			if err := json.Indent(&indented, body, "", "    "); err == nil {
+				indented.WriteByte('\n')
+				body = indented.Bytes()
+			}
+		}
+		if len(body) > 0 {
+			writer.ResponseWriter.Write(body)
+		} else {
+			writer.ResponseWriter.WriteHeaderNow()
+		}
+	}
+}
+
+// isTimestampField - Поля с моментом времени: dueDate и все *At (createdAt, completedAt, ...)
+func isTimestampField(key string) bool {
+	return key == "dueDate" || (strings.HasSuffix(key, "At") && len(key) > 2)
+}
+
+// unixTimestamps - Заменить RFC3339-строки в полях-метках времени на Unix-время в секундах
+// JSON разбирается потоком токенов, поэтому порядок полей и числа сохраняются как были.
+func unixTimestamps(body []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var out bytes.Buffer
+	if err := rewriteTimestamps(decoder, &out, ""); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// rewriteTimestamps - Переписать одно значение; key - имя поля, в котором оно лежит
+// (для элементов массива - имя самого массива)
+func rewriteTimestamps(decoder *json.Decoder, out *bytes.Buffer, key string) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	switch value := token.(type) {
+	case json.Delim:
+		if value == '{' {
+			out.WriteByte('{')
+			for i := 0; decoder.More(); i++ {
+				if i > 0 {
+					out.WriteByte(',')
+				}
+				name, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				encoded, _ := json.Marshal(name)
+				out.Write(encoded)
+				out.WriteByte(':')
+				if err := rewriteTimestamps(decoder, out, name.(string)); err != nil {
+					return err
+				}
+			}
+			out.WriteByte('}')
+		} else {
+			out.WriteByte('[')
+			for i := 0; decoder.More(); i++ {
+				if i > 0 {
+					out.WriteByte(',')
+				}
+				if err := rewriteTimestamps(decoder, out, key); err != nil {
+					return err
+				}
+			}
+			out.WriteByte(']')
+		}
+		_, err := decoder.Token() // Закрывающая скобка
+		return err
+	case string:
+		if isTimestampField(key) {
+			if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+				out.WriteString(strconv.FormatInt(t.Unix(), 10))
+				return nil
+			}
+		}
+		encoded, _ := json.Marshal(value)
+		out.Write(encoded)
+	case json.Number:
+		out.WriteString(value.String())
+	case bool:
+		out.WriteString(strconv.FormatBool(value))
+	case nil:
+		out.WriteString("null")
+	}
+	return nil
+}
+
+// timeFormat - Формат меток времени в JSON-ответах: ?timeFormat=rfc3339 (по умолчанию) или unix
+// Можно передать и заголовком X-Time-Format. Для старых клиентов, которые не разбирают RFC3339.
+func timeFormat() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := c.Query("timeFormat")
+		if format == "" {
+			format = c.GetHeader("X-Time-Format")
+		}
+		switch strings.ToLower(format) {
+		case "", "rfc3339":
+			c.Next()
+			return
+		case "unix":
+		default:
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "timeFormat must be rfc3339 or unix"})
+			return
+		}
+
+		// Тот же буфер, что и у prettyJSON: ответ переписывается целиком после обработчика
+		writer := &prettyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		body := writer.body.Bytes()
+		if strings.HasPrefix(writer.Header().Get("Content-Type"), "application/json") {
+			if rewritten, err := unixTimestamps(body); err == nil {
+				body = rewritten
+			}
+		}
+		if len(body) > 0 {
+			writer.ResponseWriter.Write(body)
+		} else {
+			writer.ResponseWriter.WriteHeaderNow()
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestUnixTimestamps(t *testing.T) {
+	body := `{"id":7,"title":"Задача с 2026-03-01T10:00:00Z","dueDate":"2026-03-01T10:00:00Z","createdAt":"2026-03-01T10:00:00.5+03:00",` +
+		`"completedAt":null,"at":"2026-03-01T10:00:00Z","history":[{"changedAt":"1970-01-01T00:01:40Z"}],"pinned":false}`
+	want := `{"id":7,"title":"Задача с 2026-03-01T10:00:00Z","dueDate":1772359200,"createdAt":1772348400,` +
+		`"completedAt":null,"at":"2026-03-01T10:00:00Z","history":[{"changedAt":100}],"pinned":false}`
+	got, err := unixTimestamps([]byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("unixTimestamps =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestTimeFormatMiddleware - ?timeFormat=unix и X-Time-Format переписывают метки времени,
+// без них ответ не меняется, неизвестный формат - 400
+func TestTimeFormatMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(timeFormat())
+	due := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	router.GET("/task", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"dueDate": due}) })
+
+	tests := []struct {
+		query, header string
+		status        int
+		body          string
+	}{
+		{status: http.StatusOK, body: `{"dueDate":"2026-03-01T10:00:00Z"}`},
+		{query: "?timeFormat=unix", status: http.StatusOK, body: `{"dueDate":1772359200}`},
+		{header: "unix", status: http.StatusOK, body: `{"dueDate":1772359200}`},
+		{query: "?timeFormat=iso", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/task"+tt.query, nil)
+		if tt.header != "" {
+			req.Header.Set("X-Time-Format", tt.header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.status || (tt.body != "" && w.Body.String() != tt.body) {
+			t.Errorf("GET /task%s (header %q) = %d %s, want %d %s", tt.query, tt.header, w.Code, w.Body.String(), tt.status, tt.body)
+		}
+	}
+}
@@ -0,0 +1,726 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// --- Версионные миграции ---
+
+// SchemaMigration - Запись о примененной миграции
+type SchemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+// migration - Один шаг изменения схемы с применением (Up) и откатом (Down)
+type migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// execSQL - Шаг миграции из набора SQL-выражений, выполняемых по порядку
+func execSQL(statements ...string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		for _, stmt := range statements {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// migrations - Все миграции по порядку. Новые добавляются только в конец,
+// уже выпущенные миграции менять нельзя.
+var migrations = []migration{
+	{
+		Version: 1,
+		Name:    "create_tasks",
+		// Как выпущено: AutoMigrate создает таблицу или дополняет созданную до миграций.
+		// Модель зафиксирована (taskSchemaV1): текущая Task создала бы колонки следующих миграций.
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&taskSchemaV1{})
+		},
+		Down: execSQL("DROP TABLE IF EXISTS tasks"),
+	},
+	{
+		Version: 2,
+		Name:    "task_indexes",
+		Up: func(tx *gorm.DB) error {
+			// Расширение pg_trgm нужно для триграммного индекса и нечеткого поиска по названию
+			if err := tx.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+				return err
+			}
+			return ensureIndexes(tx)
+		},
+		Down: func(tx *gorm.DB) error {
+			for _, idx := range taskIndexes {
+				if err := tx.Exec("DROP INDEX IF EXISTS " + idx.Name).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 3,
+		Name:    "create_time_entries",
+		Up: execSQL(
+			`CREATE TABLE time_entries (
+				id bigserial PRIMARY KEY,
+				task_id bigint NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+				started_at timestamptz NOT NULL,
+				ended_at timestamptz,
+				duration_seconds bigint NOT NULL DEFAULT 0,
+				note text NOT NULL DEFAULT '',
+				created_at timestamptz
+			)`,
+			"CREATE INDEX idx_time_entries_task_id ON time_entries (task_id)",
+		),
+		Down: execSQL("DROP TABLE IF EXISTS time_entries"),
+	},
+	{
+		Version: 4,
+		Name:    "create_saved_views",
+		Up: execSQL(
+			`CREATE TABLE saved_views (
+				id bigserial PRIMARY KEY,
+				name text NOT NULL,
+				filter jsonb NOT NULL DEFAULT '{}',
+				created_at timestamptz,
+				updated_at timestamptz
+			)`,
+		),
+		Down: execSQL("DROP TABLE IF EXISTS saved_views"),
+	},
+	{
+		Version: 5,
+		Name:    "create_tags",
+		Up: execSQL(
+			`CREATE TABLE tags (
+				id bigserial PRIMARY KEY,
+				name text NOT NULL,
+				color text NOT NULL,
+				created_at timestamptz,
+				updated_at timestamptz
+			)`,
+			"CREATE UNIQUE INDEX idx_tags_name ON tags (name)",
+		),
+		Down: execSQL("DROP TABLE IF EXISTS tags"),
+	},
+	{
+		Version: 6,
+		Name:    "add_tasks_last_activity_at",
+		Up: execSQL(
+			"ALTER TABLE tasks ADD COLUMN last_activity_at timestamptz",
+			"UPDATE tasks SET last_activity_at = COALESCE(updated_at, created_at)",
+			"CREATE INDEX idx_tasks_last_activity_at ON tasks (last_activity_at)",
+		),
+		Down: execSQL("ALTER TABLE tasks DROP COLUMN IF EXISTS last_activity_at"),
+	},
+	{
+		Version: 7,
+		Name:    "add_tasks_position",
+		Up: execSQL(
+			"ALTER TABLE tasks ADD COLUMN position integer NOT NULL DEFAULT 0",
+			// Начальный порядок внутри каждого родителя - по id
+			`UPDATE tasks SET position = ordered.rn - 1
+			FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY parent_id ORDER BY id) AS rn FROM tasks) AS ordered
+			WHERE tasks.id = ordered.id`,
+		),
+		Down: execSQL("ALTER TABLE tasks DROP COLUMN IF EXISTS position"),
+	},
+	{
+		Version: 8,
+		Name:    "add_tasks_external_id",
+		Up: execSQL(
+			"ALTER TABLE tasks ADD COLUMN external_id text",
+			"CREATE UNIQUE INDEX idx_tasks_external_id ON tasks (external_id)",
+		),
+		Down: execSQL("ALTER TABLE tasks DROP COLUMN IF EXISTS external_id"),
+	},
+	{
+		Version: 9,
+		Name:    "canonical_task_priorities",
+		Up: execSQL(
+			`UPDATE tasks SET priority = CASE lower(trim(priority))
+				WHEN 'высокий' THEN 'high'
+				WHEN 'средний' THEN 'medium'
+				WHEN 'низкий' THEN 'low'
+				ELSE lower(trim(priority))
+			END
+			WHERE priority IS NOT NULL`,
+			`UPDATE saved_views SET filter = jsonb_set(filter, '{priority}', to_jsonb(CASE lower(filter->>'priority')
+				WHEN 'высокий' THEN 'high'
+				WHEN 'средний' THEN 'medium'
+				WHEN 'низкий' THEN 'low'
+				ELSE filter->>'priority'
+			END))
+			WHERE jsonb_typeof(filter) = 'object' AND filter ? 'priority'`,
+		),
+		Down: execSQL(
+			`UPDATE tasks SET priority = CASE priority
+				WHEN 'high' THEN 'высокий'
+				WHEN 'medium' THEN 'средний'
+				WHEN 'low' THEN 'низкий'
+				ELSE priority
+			END`,
+		),
+	},
+	{
+		Version: 10,
+		Name:    "add_tasks_search_vector",
+		// Вектор поддерживается триггером, поэтому модель Task о нем не знает.
+		// Название весит больше описания (веса A и B для ts_rank).
+		Up: execSQL(
+			"ALTER TABLE tasks ADD COLUMN IF NOT EXISTS search_vector tsvector",
+			`CREATE OR REPLACE FUNCTION tasks_search_vector_update() RETURNS trigger AS $$
+			BEGIN
+				NEW.search_vector :=
+					setweight(to_tsvector('simple', coalesce(NEW.title, '')), 'A') ||
+					setweight(to_tsvector('simple', coalesce(NEW.description, '')), 'B');
+				RETURN NEW;
+			END
+			$$ LANGUAGE plpgsql`,
+			`CREATE TRIGGER tasks_search_vector_trigger
+				BEFORE INSERT OR UPDATE OF title, description ON tasks
+				FOR EACH ROW EXECUTE FUNCTION tasks_search_vector_update()`,
+			`UPDATE tasks SET search_vector =
+				setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+				setweight(to_tsvector('simple', coalesce(description, '')), 'B')`,
+			"CREATE INDEX IF NOT EXISTS idx_tasks_search_vector ON tasks USING GIN (search_vector)",
+		),
+		Down: execSQL(
+			"DROP INDEX IF EXISTS idx_tasks_search_vector",
+			"DROP TRIGGER IF EXISTS tasks_search_vector_trigger ON tasks",
+			"DROP FUNCTION IF EXISTS tasks_search_vector_update()",
+			"ALTER TABLE tasks DROP COLUMN IF EXISTS search_vector",
+		),
+	},
+	{
+		Version: 11,
+		Name:    "add_tasks_status",
+		Up: execSQL(
+			"ALTER TABLE tasks ADD COLUMN IF NOT EXISTS status varchar(20) NOT NULL DEFAULT 'todo'",
+			"UPDATE tasks SET status = 'done' WHERE is_completed",
+			"ALTER TABLE tasks ADD CONSTRAINT chk_tasks_status CHECK (status IN ('todo', 'in_progress', 'blocked', 'done'))",
+			"CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks (status)",
+		),
+		Down: execSQL(
+			"DROP INDEX IF EXISTS idx_tasks_status",
+			"ALTER TABLE tasks DROP COLUMN IF EXISTS status",
+		),
+	},
+	{
+		Version: 12,
+		Name:    "unique_active_task_titles",
+		// Удаленные задачи не участвуют в индексе, поэтому их названия можно использовать снова.
+		// Колонки created_by еще нет: до миграции 31 названия уникальны в пределах родителя.
+		Up: func(tx *gorm.DB) error {
+			if err := checkDuplicateTitles(tx, "COALESCE(parent_id, 0)"); err != nil {
+				return err
+			}
+			return tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_active_title ON tasks (COALESCE(parent_id, 0), title) WHERE deleted_at IS NULL").Error
+		},
+		Down: execSQL("DROP INDEX IF EXISTS idx_tasks_active_title"),
+	},
+	{
+		Version: 13,
+		Name:    "add_tasks_archived_at",
+		Up: execSQL(
+			"ALTER TABLE tasks ADD COLUMN IF NOT EXISTS archived_at timestamptz",
+			"CREATE INDEX IF NOT EXISTS idx_tasks_archived_at ON tasks (archived_at)",
+		),
+		Down: execSQL(
+			"DROP INDEX IF EXISTS idx_tasks_archived_at",
+			"ALTER TABLE tasks DROP COLUMN IF EXISTS archived_at",
+		),
+	},
+	{
+		Version: 14,
+		Name:    "add_tasks_created_by",
+		Up: execSQL(
+			"ALTER TABLE tasks ADD COLUMN IF NOT EXISTS created_by varchar(255)",
+			"CREATE INDEX IF NOT EXISTS idx_tasks_created_by ON tasks (created_by)",
+		),
+		Down: execSQL(
+			"DROP INDEX IF EXISTS idx_tasks_created_by",
+			"ALTER TABLE tasks DROP COLUMN IF EXISTS created_by",
+		),
+	},
+	{
+		Version: 15,
+		Name:    "create_checklist_items",
+		Up: execSQL(
+			`CREATE TABLE checklist_items (
+				id bigserial PRIMARY KEY,
+				task_id bigint NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+				text text NOT NULL,
+				done boolean NOT NULL DEFAULT false,
+				position integer NOT NULL DEFAULT 0,
+				created_at timestamptz,
+				updated_at timestamptz
+			)`,
+			"CREATE INDEX idx_checklist_items_task_id ON checklist_items (task_id, position)",
+		),
+		Down: execSQL("DROP TABLE IF EXISTS checklist_items"),
+	},
+	{
+		Version: 16,
+		Name:    "add_tasks_assignee_id",
+		Up: execSQL(
+			"ALTER TABLE tasks ADD COLUMN IF NOT EXISTS assignee_id varchar(255)",
+			"CREATE INDEX IF NOT EXISTS idx_tasks_assignee_id ON tasks (assignee_id)",
+		),
+		Down: execSQL(
+			"DROP INDEX IF EXISTS idx_tasks_assignee_id",
+			"ALTER TABLE tasks DROP COLUMN IF EXISTS assignee_id",
+		),
+	},
+	{
+		Version: 17,
+		Name:    "create_task_history",
+		Up: execSQL(
+			`CREATE TABLE task_history (
+				id bigserial PRIMARY KEY,
+				task_id bigint NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+				version integer NOT NULL,
+				snapshot jsonb NOT NULL,
+				changed_at timestamptz NOT NULL,
+				UNIQUE (task_id, version)
+			)`,
+		),
+		Down: execSQL("DROP TABLE IF EXISTS task_history"),
+	},
+	{
+		Version: 18,
+		Name:    "create_task_links",
+		Up: execSQL(
+			`CREATE TABLE task_links (
+				id bigserial PRIMARY KEY,
+				task_id bigint NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+				url text NOT NULL,
+				title text NOT NULL DEFAULT '',
+				created_at timestamptz
+			)`,
+			"CREATE INDEX idx_task_links_task_id ON task_links (task_id)",
+		),
+		Down: execSQL("DROP TABLE IF EXISTS task_links"),
+	},
+	{
+		Version: 19,
+		Name:    "add_tasks_completed_at",
+		Up: execSQL(
+			"ALTER TABLE tasks ADD COLUMN completed_at timestamptz",
+			// Точное время завершения старых задач неизвестно, берем время последнего изменения
+			"UPDATE tasks SET completed_at = updated_at WHERE is_completed",
+			"CREATE INDEX idx_tasks_completed_unarchived ON tasks (completed_at) WHERE is_completed AND archived_at IS NULL",
+		),
+		Down: execSQL(
+			"DROP INDEX IF EXISTS idx_tasks_completed_unarchived",
+			"ALTER TABLE tasks DROP COLUMN IF EXISTS completed_at",
+		),
+	},
+	{
+		Version: 20,
+		Name:    "create_reminders",
+		Up: execSQL(
+			`CREATE TABLE reminders (
+				id bigserial PRIMARY KEY,
+				task_id bigint NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+				before_minutes integer CHECK (before_minutes >= 0),
+				at_time text,
+				days_before integer NOT NULL DEFAULT 0,
+				fired_for timestamptz,
+				created_at timestamptz,
+				CHECK ((before_minutes IS NULL) <> (at_time IS NULL))
+			)`,
+			"CREATE INDEX idx_reminders_task_id ON reminders (task_id)",
+		),
+		Down: execSQL("DROP TABLE IF EXISTS reminders"),
+	},
+	{
+		Version: 21,
+		Name:    "create_task_dependencies",
+		Up: execSQL(
+			`CREATE TABLE task_dependencies (
+				task_id bigint NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+				depends_on_id bigint NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+				created_at timestamptz,
+				PRIMARY KEY (task_id, depends_on_id),
+				CHECK (task_id <> depends_on_id)
+			)`,
+			"CREATE INDEX idx_task_dependencies_depends_on ON task_dependencies (depends_on_id)",
+		),
+		Down: execSQL("DROP TABLE IF EXISTS task_dependencies"),
+	},
+	{
+		Version: 22,
+		Name:    "create_user_settings",
+		Up: execSQL(
+			`CREATE TABLE user_settings (
+				user_id text PRIMARY KEY,
+				timezone text NOT NULL DEFAULT '',
+				updated_at timestamptz
+			)`,
+		),
+		Down: execSQL("DROP TABLE IF EXISTS user_settings"),
+	},
+	{
+		Version: 23,
+		Name:    "add_tasks_title_prefix_index",
+		// text_pattern_ops позволяет использовать индекс для LIKE 'prefix%' при любой локали
+		Up: execSQL(
+			"CREATE INDEX idx_tasks_title_prefix ON tasks (lower(title) text_pattern_ops) WHERE deleted_at IS NULL",
+		),
+		Down: execSQL("DROP INDEX IF EXISTS idx_tasks_title_prefix"),
+	},
+	{
+		Version: 24,
+		Name:    "create_webhooks",
+		Up: execSQL(
+			`CREATE TABLE webhooks (
+				id bigserial PRIMARY KEY,
+				url text NOT NULL,
+				events text NOT NULL DEFAULT '',
+				created_at timestamptz
+			)`,
+			`CREATE TABLE webhook_deliveries (
+				id bigserial PRIMARY KEY,
+				webhook_id bigint NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+				event text NOT NULL,
+				payload text NOT NULL,
+				status_code integer,
+				error text NOT NULL DEFAULT '',
+				attempts integer NOT NULL DEFAULT 0,
+				created_at timestamptz,
+				last_attempt_at timestamptz,
+				next_attempt_at timestamptz,
+				delivered_at timestamptz
+			)`,
+			"CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id, id)",
+			// Очередь диспетчера: только доставки, которые еще будут отправляться
+			"CREATE INDEX idx_webhook_deliveries_next_attempt ON webhook_deliveries (next_attempt_at) WHERE next_attempt_at IS NOT NULL",
+		),
+		Down: execSQL("DROP TABLE IF EXISTS webhook_deliveries", "DROP TABLE IF EXISTS webhooks"),
+	},
+	{
+		Version: 25,
+		Name:    "add_tasks_pinned",
+		Up: execSQL(
+			"ALTER TABLE tasks ADD COLUMN pinned boolean NOT NULL DEFAULT false",
+			// Закрепленных задач единицы: частичный индекс для подсчета лимита
+			"CREATE INDEX idx_tasks_pinned ON tasks (id) WHERE pinned AND deleted_at IS NULL",
+		),
+		Down: execSQL("DROP INDEX IF EXISTS idx_tasks_pinned", "ALTER TABLE tasks DROP COLUMN IF EXISTS pinned"),
+	},
+	{
+		Version: 26,
+		Name:    "add_tasks_flag",
+		Up:      execSQL("ALTER TABLE tasks ADD COLUMN flag text NOT NULL DEFAULT ''"),
+		Down:    execSQL("ALTER TABLE tasks DROP COLUMN IF EXISTS flag"),
+	},
+	{
+		Version: 27,
+		Name:    "create_undo_tokens",
+		Up: execSQL(
+			`CREATE TABLE undo_tokens (
+				token text PRIMARY KEY,
+				task_id bigint NOT NULL,
+				expires_at timestamptz NOT NULL,
+				created_at timestamptz
+			)`,
+			"CREATE INDEX idx_undo_tokens_task_id ON undo_tokens (task_id)",
+			"CREATE INDEX idx_undo_tokens_expires_at ON undo_tokens (expires_at)",
+		),
+		Down: execSQL("DROP TABLE IF EXISTS undo_tokens"),
+	},
+	{
+		Version: 28,
+		Name:    "add_user_settings_quiet_hours",
+		Up:      execSQL("ALTER TABLE user_settings ADD COLUMN quiet_hours text NOT NULL DEFAULT ''"),
+		Down:    execSQL("ALTER TABLE user_settings DROP COLUMN IF EXISTS quiet_hours"),
+	},
+	{
+		Version: 29,
+		Name:    "add_tasks_overdue_index",
+		// Просрочка зависит от now() и не может быть сгенерированной колонкой, поэтому
+		// открытые задачи со сроком лежат в частичном индексе по due_date. Условие индекса
+		// должно следовать из whereOverdue и мягкого удаления GORM (deleted_at IS NULL)
+		Up: execSQL(
+			"CREATE INDEX idx_tasks_overdue ON tasks (due_date) WHERE NOT is_completed AND due_date IS NOT NULL AND deleted_at IS NULL",
+		),
+		Down: execSQL("DROP INDEX IF EXISTS idx_tasks_overdue"),
+	},
+	{
+		Version: 30,
+		Name:    "unique_active_task_titles_per_user",
+		// Названия уникальны у каждого пользователя (задачи без автора - как у одного пользователя)
+		// и по-прежнему в пределах родителя: копии подзадач при дублировании сохраняют названия.
+		// Новый ключ мягче прежнего, поэтому повторов при переходе быть не может.
+		Up: execSQL(
+			"DROP INDEX IF EXISTS idx_tasks_active_title",
+			"CREATE UNIQUE INDEX idx_tasks_active_title ON tasks (COALESCE(created_by, ''), COALESCE(parent_id, 0), title) WHERE deleted_at IS NULL",
+		),
+		Down: func(tx *gorm.DB) error {
+			if err := checkDuplicateTitles(tx, "COALESCE(parent_id, 0)"); err != nil {
+				return err
+			}
+			return execSQL(
+				"DROP INDEX IF EXISTS idx_tasks_active_title",
+				"CREATE UNIQUE INDEX idx_tasks_active_title ON tasks (COALESCE(parent_id, 0), title) WHERE deleted_at IS NULL",
+			)(tx)
+		},
+	},
+	{
+		Version: 31,
+		Name:    "add_tasks_user_created_at_indexes",
+		// Списки пользователя (scopeToUser) фильтруются по автору или исполнителю и сортируются
+		// по created_at; колонки пользователя появились после миграции 2, поэтому индексы отдельно
+		Up: execSQL(
+			"CREATE INDEX idx_tasks_created_by_created_at ON tasks (created_by, created_at)",
+			"CREATE INDEX idx_tasks_assignee_created_at ON tasks (assignee_id, created_at)",
+		),
+		Down: execSQL(
+			"DROP INDEX IF EXISTS idx_tasks_created_by_created_at",
+			"DROP INDEX IF EXISTS idx_tasks_assignee_created_at",
+		),
+	},
+}
+
+// checkDuplicateTitles - Ошибка со списком активных задач, названия которых повторяются в пределах key
+// Повторы не переименовываются автоматически: какую из задач переименовать, решает пользователь.
+func checkDuplicateTitles(tx *gorm.DB, key string) error {
+	var duplicates []struct {
+		Title string
+		IDs   string `gorm:"column:ids"`
+	}
+	err := tx.Raw(`SELECT title, string_agg(id::text, ', ' ORDER BY id) AS ids FROM tasks
+		WHERE deleted_at IS NULL
+		GROUP BY ` + key + `, title HAVING COUNT(*) > 1
+		ORDER BY MIN(id) LIMIT 50`).Scan(&duplicates).Error
+	if err != nil || len(duplicates) == 0 {
+		return err
+	}
+	conflicts := make([]string, len(duplicates))
+	for i, d := range duplicates {
+		conflicts[i] = fmt.Sprintf("%q (ids %s)", d.Title, d.IDs)
+	}
+	return fmt.Errorf("active tasks with duplicate titles must be renamed or deleted first: %s", strings.Join(conflicts, "; "))
+}
+
+// taskSchemaV1 - Модель задачи на момент выпуска миграции 1 (не менять вместе с Task)
+type taskSchemaV1 struct {
+	ID          uint `gorm:"primaryKey"`
+	Title       string
+	Description string
+	Priority    string
+	DueDate     *time.Time
+	Tags        string
+	IsCompleted bool
+	ParentID    *uint `gorm:"index"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+}
+
+func (taskSchemaV1) TableName() string {
+	return "tasks"
+}
+
+// expectedSchemaVersion - Версия схемы, которую ожидает текущая сборка
+func expectedSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// currentSchemaVersion - Последняя примененная версия схемы
+func currentSchemaVersion(conn *gorm.DB) (int, error) {
+	var version int
+	err := conn.Model(&SchemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	return version, err
+}
+
+// ensureMigrationsTable - Создать таблицу учета примененных миграций
+func ensureMigrationsTable() error {
+	return db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version integer PRIMARY KEY,
+		name text NOT NULL,
+		applied_at timestamptz NOT NULL
+	)`).Error
+}
+
+// runMigrations - Применить все еще не примененные миграции, каждую в своей транзакции
+func runMigrations() error {
+	if err := ensureMigrationsTable(); err != nil {
+		return fmt.Errorf("create schema_migrations table: %w", err)
+	}
+
+	current, err := currentSchemaVersion(db)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.Version, m.Name, err)
+		}
+		log.Printf("Applied migration %d: %s", m.Version, m.Name)
+	}
+	return nil
+}
+
+// rollbackMigrations - Откатить последние steps примененных миграций
+func rollbackMigrations(steps int) error {
+	if err := ensureMigrationsTable(); err != nil {
+		return fmt.Errorf("create schema_migrations table: %w", err)
+	}
+
+	for i := 0; i < steps; i++ {
+		current, err := currentSchemaVersion(db)
+		if err != nil {
+			return err
+		}
+		if current == 0 {
+			log.Println("No migrations to roll back.")
+			return nil
+		}
+
+		var m *migration
+		for j := range migrations {
+			if migrations[j].Version == current {
+				m = &migrations[j]
+			}
+		}
+		if m == nil {
+			return fmt.Errorf("migration %d is applied but unknown to this build", current)
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Down(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, m.Version).Error
+		})
+		if err != nil {
+			return fmt.Errorf("rollback %d (%s): %w", m.Version, m.Name, err)
+		}
+		log.Printf("Rolled back migration %d: %s", m.Version, m.Name)
+	}
+	return nil
+}
+
+// runMigrateCommand - Обработать команду migrate из командной строки
+func runMigrateCommand(args []string) {
+	if len(args) == 0 {
+		log.Fatal("Usage: migrate up | down [N] | status")
+	}
+
+	switch args[0] {
+	case "up":
+		if err := runMigrations(); err != nil {
+			log.Fatalf("Failed to migrate database schema: %v", err)
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				log.Fatalf("Invalid number of steps %q", args[1])
+			}
+			steps = n
+		}
+		if err := rollbackMigrations(steps); err != nil {
+			log.Fatalf("Failed to roll back migrations: %v", err)
+		}
+	case "status":
+		if err := ensureMigrationsTable(); err != nil {
+			log.Fatalf("Failed to create schema_migrations table: %v", err)
+		}
+		current, err := currentSchemaVersion(db)
+		if err != nil {
+			log.Fatalf("Failed to read schema version: %v", err)
+		}
+		log.Printf("Schema version %d, expected %d", current, expectedSchemaVersion())
+	default:
+		log.Fatalf("Unknown migrate command %q", args[0])
+	}
+}
+
+// Readyz - Проверка готовности: БД доступна и все миграции применены
+func Readyz(c *gin.Context) {
+	expected := expectedSchemaVersion()
+	current, err := currentSchemaVersion(db)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "error": "Database is not reachable"})
+		return
+	}
+
+	status := gin.H{
+		"status":          "ok",
+		"schemaVersion":   current,
+		"expectedVersion": expected,
+	}
+	if current < expected {
+		status["status"] = "migrations_pending"
+		status["pendingMigrations"] = expected - current
+		c.JSON(http.StatusServiceUnavailable, status)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// --- Индексы для ускорения запросов ---
+
+// indexDefinition - Описание индекса, создаваемого миграцией
+type indexDefinition struct {
+	Name string
+	SQL  string
+}
+
+// Индексы под фильтры и сортировки списка задач (миграция 2).
+// Составные индексы по пользователю и created_at создает миграция 31.
+var taskIndexes = []indexDefinition{
+	{"idx_tasks_is_completed", "CREATE INDEX IF NOT EXISTS idx_tasks_is_completed ON tasks (is_completed)"},
+	{"idx_tasks_priority", "CREATE INDEX IF NOT EXISTS idx_tasks_priority ON tasks (priority)"},
+	{"idx_tasks_due_date", "CREATE INDEX IF NOT EXISTS idx_tasks_due_date ON tasks (due_date)"},
+	{"idx_tasks_created_at", "CREATE INDEX IF NOT EXISTS idx_tasks_created_at ON tasks (created_at)"},
+	{"idx_tasks_title_trgm", "CREATE INDEX IF NOT EXISTS idx_tasks_title_trgm ON tasks USING gin (title gin_trgm_ops)"},
+}
+
+// ensureIndexes - Создать недостающие индексы и залогировать созданные
+func ensureIndexes(tx *gorm.DB) error {
+	for _, idx := range taskIndexes {
+		var exists bool
+		if err := tx.Raw("SELECT EXISTS (SELECT 1 FROM pg_indexes WHERE indexname = ?)", idx.Name).Scan(&exists).Error; err != nil {
+			return fmt.Errorf("check index %s: %w", idx.Name, err)
+		}
+		if exists {
+			continue
+		}
+		if err := tx.Exec(idx.SQL).Error; err != nil {
+			return fmt.Errorf("create index %s: %w", idx.Name, err)
+		}
+		log.Printf("Created index %s", idx.Name)
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TestMigrationsUpgradeBaselineSchema - Миграции применяются к таблице tasks, созданной до версионных миграций
+// (AutoMigrate первой версии: без parent_id и deleted_at), и сохраняют ее строки.
+func TestMigrationsUpgradeBaselineSchema(t *testing.T) {
+	setupTestDB(t)
+	conn := testSchemaDB(t, "test_baseline_schema")
+	err := conn.Exec(`CREATE TABLE tasks (
+		id bigserial PRIMARY KEY,
+		title text,
+		description text,
+		priority text,
+		due_date timestamptz,
+		tags text,
+		is_completed boolean,
+		created_at timestamptz,
+		updated_at timestamptz
+	)`).Error
+	if err != nil {
+		t.Fatalf("create baseline table: %v", err)
+	}
+	if err := conn.Exec("INSERT INTO tasks (title, priority, is_completed) VALUES ('Старая задача', 'высокий', false)").Error; err != nil {
+		t.Fatalf("insert baseline task: %v", err)
+	}
+
+	saved := db
+	db = conn
+	t.Cleanup(func() { db = saved })
+	if err := runMigrations(); err != nil {
+		t.Fatalf("runMigrations on baseline schema: %v", err)
+	}
+
+	version, err := currentSchemaVersion(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != expectedSchemaVersion() {
+		t.Errorf("schema version = %d, want %d", version, expectedSchemaVersion())
+	}
+	var task Task
+	if err := conn.Where("title = ?", "Старая задача").First(&task).Error; err != nil {
+		t.Fatalf("baseline task after migrations: %v", err)
+	}
+	if task.Priority != PriorityHigh || task.ParentID != nil {
+		t.Errorf("baseline task = priority %q, parent %v; want %q without parent", task.Priority, task.ParentID, PriorityHigh)
+	}
+}
+
+// TestCheckDuplicateTitles - Повторы названий останавливают миграцию списком задач, а не переименовываются
+func TestCheckDuplicateTitles(t *testing.T) {
+	setupTestDB(t)
+	conn := testSchemaDB(t, "test_duplicate_titles")
+	err := conn.Exec(`CREATE TABLE tasks (id bigserial PRIMARY KEY, title text, parent_id bigint, deleted_at timestamptz)`).Error
+	if err == nil {
+		err = conn.Exec(`INSERT INTO tasks (title, parent_id, deleted_at) VALUES
+			('Купить молоко', NULL, NULL), ('Купить молоко', NULL, NULL), ('Купить молоко', 1, NULL),
+			('Позвонить', NULL, NULL), ('Позвонить', NULL, now())`).Error
+	}
+	if err != nil {
+		t.Fatalf("prepare tasks: %v", err)
+	}
+
+	err = checkDuplicateTitles(conn, "COALESCE(parent_id, 0)")
+	if err == nil || !strings.Contains(err.Error(), `"Купить молоко" (ids 1, 2)`) {
+		t.Fatalf("checkDuplicateTitles = %v, want the conflicting ids 1, 2", err)
+	}
+	if strings.Contains(err.Error(), "Позвонить") {
+		t.Errorf("deleted task is reported as a conflict: %v", err)
+	}
+	var renamed int64
+	conn.Table("tasks").Where("title <> 'Купить молоко' AND title <> 'Позвонить'").Count(&renamed)
+	if renamed != 0 {
+		t.Errorf("%d titles were changed", renamed)
+	}
+}
+
+// explainPlan - План запроса query (строки EXPLAIN), выполненного в tx
+func explainPlan(t *testing.T, tx *gorm.DB, query func(*gorm.DB) *gorm.DB) string {
+	t.Helper()
+	var tasks []Task
+	stmt := query(tx.Session(&gorm.Session{DryRun: true}).Model(&Task{})).Find(&tasks).Statement
+	var plan []string
+	if err := tx.Raw("EXPLAIN "+stmt.SQL.String(), stmt.Vars...).Scan(&plan).Error; err != nil {
+		t.Fatalf("EXPLAIN %s: %v", stmt.SQL.String(), err)
+	}
+	return strings.Join(plan, "\n")
+}
+
+// TestOverdueQueriesUseIndex - Просроченные задачи (пресет overdue, сводка, дайджест) ищутся
+// по частичному индексу idx_tasks_overdue: его условие следует из условия запроса
+func TestOverdueQueriesUseIndex(t *testing.T) {
+	setupTestDB(t)
+	past := time.Now().AddDate(0, 0, -3)
+	tasks := make([]Task, 0, 2010)
+	for i := range 2000 {
+		tasks = append(tasks, Task{Title: fmt.Sprintf("Готово %d", i), Status: StatusDone, IsCompleted: true, DueDate: &past, CompletedAt: &past})
+	}
+	for i := range 10 {
+		tasks = append(tasks, Task{Title: fmt.Sprintf("Просрочено %d", i), DueDate: &past})
+	}
+	if err := db.Session(&gorm.Session{SkipHooks: true}).CreateInBatches(tasks, 500).Error; err != nil {
+		t.Fatalf("create tasks: %v", err)
+	}
+	if err := db.Exec("ANALYZE tasks").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	overdue, err := parseTaskFilter(url.Values{"filter": {"overdue"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	queries := map[string]func(*gorm.DB) *gorm.DB{
+		"filter=overdue": func(q *gorm.DB) *gorm.DB { return applyTaskFilter(q, overdue) },
+		"whereOverdue":   func(q *gorm.DB) *gorm.DB { return whereOverdue(q, time.Now()) },
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Без последовательного чтения планировщик берет индекс, только если может доказать его условие
+		if err := tx.Exec("SET LOCAL enable_seqscan = off").Error; err != nil {
+			return err
+		}
+		for name, query := range queries {
+			if plan := explainPlan(t, tx, query); !strings.Contains(plan, "idx_tasks_overdue") {
+				t.Errorf("%s does not use idx_tasks_overdue:\n%s", name, plan)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestWhereOverdueMatchesIndex - Условие просрочки пишется без параметра у is_completed:
+// с NOT is_completed = $1 планировщик не смог бы сопоставить его с условием индекса
+func TestWhereOverdueMatchesIndex(t *testing.T) {
+	var tasks []Task
+	sql := whereOverdue(dryRunDB(t).Model(&Task{}), time.Now()).Find(&tasks).Statement.SQL.String()
+	if !strings.Contains(sql, "NOT is_completed AND due_date < $1") || !strings.Contains(sql, `"tasks"."deleted_at" IS NULL`) {
+		t.Errorf("overdue query = %s", sql)
+	}
+}
+
+// TestMigrationVersionsSequential - Версии миграций идут подряд с 1: откат ищет миграцию по номеру
+func TestMigrationVersionsSequential(t *testing.T) {
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("migration %q has version %d, want %d", m.Name, m.Version, i+1)
+		}
+	}
+}
+
+// TestUserCreatedAtIndexes - Для списков пользователя есть индексы (created_by, created_at)
+// и (assignee_id, created_at)
+func TestUserCreatedAtIndexes(t *testing.T) {
+	setupTestDB(t)
+	for _, want := range []struct{ name, columns string }{
+		{"idx_tasks_created_by_created_at", "(created_by, created_at)"},
+		{"idx_tasks_assignee_created_at", "(assignee_id, created_at)"},
+	} {
+		var definition string
+		if err := db.Raw("SELECT indexdef FROM pg_indexes WHERE indexname = ?", want.name).Scan(&definition).Error; err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(definition, want.columns) {
+			t.Errorf("index %s = %q, want columns %s", want.name, definition, want.columns)
+		}
+	}
+}
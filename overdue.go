@@ -0,0 +1,83 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// --- Сводка по просроченным задачам ---
+
+// overdueAgeBucket - Интервал просрочки в днях для задачи, просроченной на days дней (days >= 1)
+const overdueAgeBucket = `CASE WHEN days <= 3 THEN '1-3' WHEN days <= 7 THEN '4-7' ELSE '8+' END`
+
+// overdueAgeBuckets - Интервалы просрочки в порядке вывода
+var overdueAgeBuckets = []string{"1-3", "4-7", "8+"}
+
+// countOverdueBy - Число задач по значению выражения group над выборкой с колонкой days
+func countOverdueBy(query *gorm.DB, group string) (map[string]int64, error) {
+	var rows []struct {
+		Key   string
+		Count int64
+	}
+	err := query.Session(&gorm.Session{NewDB: true}).Table("(?) AS overdue", query).
+		Select(group + " AS key, COUNT(*) AS count").
+		Group("key").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Key] = row.Count
+	}
+	return counts, nil
+}
+
+// GetOverdueSummary - Просроченные задачи по приоритетам и по сроку просрочки (GET /tasks/overdue/summary)
+// Просроченной считается открытая неархивная задача со сроком раньше начала сегодняшнего дня
+// в поясе ?tz= (или поясе из настроек пользователя); задача со сроком вчера просрочена на 1 день.
+// Задачи без приоритета считаются под ключом "none".
+func GetOverdueSummary(c *gin.Context) {
+	loc, err := requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	today := startOfDay(time.Now(), loc)
+
+	overdue := whereOverdue(scopeToUser(c, txFromContext(c).Model(&Task{})), today).
+		Select("priority, ?::date - (due_date AT TIME ZONE ?)::date AS days", today.Format(time.DateOnly), postgresTimezone(loc))
+
+	byPriority, err := countOverdueBy(overdue, "priority")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute overdue summary"})
+		return
+	}
+	byAge, err := countOverdueBy(overdue, overdueAgeBucket)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute overdue summary"})
+		return
+	}
+
+	var total int64
+	ages := make(gin.H, len(overdueAgeBuckets))
+	for _, bucket := range overdueAgeBuckets {
+		ages[bucket] = byAge[bucket]
+		total += byAge[bucket]
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"timezone": loc.String(),
+		"asOf":     today,
+		"total":    total,
+		"byPriority": gin.H{
+			PriorityHigh:   byPriority[PriorityHigh],
+			PriorityMedium: byPriority[PriorityMedium],
+			PriorityLow:    byPriority[PriorityLow],
+			"none":         byPriority[""],
+		},
+		"byDaysOverdue": ages,
+	})
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// --- Пагинация ---
+
+// Размер страницы по умолчанию и максимальный (DEFAULT_PAGE_SIZE, MAX_PAGE_SIZE)
+var (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// configurePageSizes - Применить размеры страницы из окружения
+// Некорректная пара (нули, отрицательные значения, default > max) заменяется значениями по умолчанию.
+func configurePageSizes(def, max int) {
+	if def < 1 || max < 1 || def > max {
+		log.Printf("Invalid page sizes DEFAULT_PAGE_SIZE=%d MAX_PAGE_SIZE=%d (need 1 <= default <= max), using %d and %d",
+			def, max, defaultPageSize, maxPageSize)
+		def, max = defaultPageSize, maxPageSize
+	}
+	defaultPageSize, maxPageSize = def, max
+	slog.Debug("pagination configured", "defaultPageSize", defaultPageSize, "maxPageSize", maxPageSize)
+}
+
+// Максимальное смещение для постраничной навигации (MAX_PAGINATION_OFFSET).
+// Глубокий OFFSET заставляет Postgres перебирать все пропущенные строки.
+var maxPaginationOffset = 10000
+
+// Pagination - Параметры постраничной выборки
+// Либо номер страницы (?page=&pageSize=), либо курсор (?afterId=).
+type Pagination struct {
+	Page     int  `json:"page,omitempty"`
+	PageSize int  `json:"pageSize"`
+	AfterID  uint `json:"afterId,omitempty"`
+}
+
+// parsePagination - Разобрать параметры пагинации из строки запроса
+func parsePagination(values url.Values) (Pagination, error) {
+	p := Pagination{Page: 1, PageSize: defaultPageSize}
+
+	if raw := values.Get("pageSize"); raw != "" {
+		size, err := strconv.Atoi(raw)
+		if err != nil || size < 1 || size > maxPageSize {
+			return p, fmt.Errorf("pageSize must be between 1 and %d", maxPageSize)
+		}
+		p.PageSize = size
+	}
+	if raw := values.Get("afterId"); raw != "" {
+		afterID, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return p, fmt.Errorf("invalid afterId value %q", raw)
+		}
+		p.AfterID = uint(afterID)
+		p.Page = 0
+		return p, nil
+	}
+	if raw := values.Get("page"); raw != "" {
+		page, err := strconv.Atoi(raw)
+		if err != nil || page < 1 {
+			return p, fmt.Errorf("page must be a positive integer")
+		}
+		p.Page = page
+	}
+
+	if offset := p.offset(); offset > maxPaginationOffset {
+		return p, fmt.Errorf("offset %d exceeds the maximum of %d rows; use cursor pagination with ?afterId=<last seen id> instead", offset, maxPaginationOffset)
+	}
+	return p, nil
+}
+
+// offset - Смещение для постраничной навигации
+func (p Pagination) offset() int {
+	if p.Page < 1 {
+		return 0
+	}
+	return (p.Page - 1) * p.PageSize
+}
+
+// listOrder - Порядок выборки: SQL-выражение с параметрами (пустое - по id)
+// id всегда добавляется последним, чтобы страницы не пересекались при равных значениях.
+type listOrder struct {
+	SQL  string
+	Vars []interface{}
+}
+
+// expression - ORDER BY одним выражением: clause.OrderBy с Expression не сливается
+// с последующими Order(...), поэтому все части порядка собираются здесь
+func (o listOrder) expression() clause.OrderBy {
+	sql := "id"
+	if o.SQL != "" {
+		sql = o.SQL + ", id"
+	}
+	return clause.OrderBy{Expression: clause.Expr{SQL: sql, Vars: o.Vars, WithoutParentheses: true}}
+}
+
+// withPrimary - Порядок, где primary идет первым, а текущий порядок - вторичным
+func (o listOrder) withPrimary(primary string) listOrder {
+	if o.SQL == "" {
+		return listOrder{SQL: primary}
+	}
+	return listOrder{SQL: primary + ", " + o.SQL, Vars: o.Vars}
+}
+
+// paginate - Посчитать total и выбрать текущую страницу задач
+// Общий путь для всех списков: пагинация из строки запроса, порядок order,
+// вычисляемые поля и превью описаний (кроме ?fullDescription=true).
+// При ошибке ответ уже отправлен и возвращается false.
+func paginate(c *gin.Context, query *gorm.DB, order listOrder) ([]Task, ListMeta, bool) {
+	pagination, err := parsePagination(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, ListMeta{}, false
+	}
+	// Курсор имеет смысл только при сортировке по id
+	if order.SQL != "" && pagination.AfterID > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort cannot be combined with afterId cursor pagination"})
+		return nil, ListMeta{}, false
+	}
+
+	// Session делает запрос безопасным для повторного использования (Count, затем Find);
+	// контекст запроса передает id запроса в лог медленных запросов
+	query = query.Session(&gorm.Session{Context: c.Request.Context()})
+	var total int64
+	if result := query.Count(&total); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count tasks"})
+		return nil, ListMeta{}, false
+	}
+
+	tasks := []Task{}
+	if result := pageQuery(query, pagination, order).Find(&tasks); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load tasks"})
+		return nil, ListMeta{}, false
+	}
+	enrichTasks(c, tasks)
+	if c.Query("fullDescription") != "true" {
+		for i := range tasks {
+			tasks[i].toListView()
+		}
+	}
+	return tasks, pagination.meta(total, tasks), true
+}
+
+// pageQuery - Ограничить запрос текущей страницей (смещение или курсор afterId) в порядке order
+func pageQuery(query *gorm.DB, pagination Pagination, order listOrder) *gorm.DB {
+	page := query.Offset(pagination.offset())
+	if pagination.AfterID > 0 {
+		page = query.Where("id > ?", pagination.AfterID)
+	}
+	return page.Order(order.expression()).Limit(pagination.PageSize)
+}
+
+// ListMeta - Метаданные пагинации в ответе списка
+type ListMeta struct {
+	Total       int64 `json:"total"`
+	Page        int   `json:"page,omitempty"`
+	PageSize    int   `json:"pageSize"`
+	NextAfterID uint  `json:"nextAfterId,omitempty"` // Курсор следующей страницы в режиме ?afterId=
+}
+
+// meta - Метаданные для текущей страницы
+func (p Pagination) meta(total int64, tasks []Task) ListMeta {
+	meta := ListMeta{Total: total, Page: p.Page, PageSize: p.PageSize}
+	if p.AfterID > 0 && len(tasks) == p.PageSize {
+		meta.NextAfterID = tasks[len(tasks)-1].ID
+	}
+	return meta
+}
+
+// ListLinks - Ссылки на соседние страницы (относительные URL с теми же параметрами)
+type ListLinks struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// listLinks - Ссылки для текущей страницы списка
+func listLinks(c *gin.Context, meta ListMeta) ListLinks {
+	link := func(set func(url.Values)) string {
+		u := *c.Request.URL
+		values := u.Query()
+		set(values)
+		u.RawQuery = values.Encode()
+		return u.RequestURI()
+	}
+	links := ListLinks{Self: c.Request.URL.RequestURI()}
+	switch {
+	case meta.NextAfterID > 0:
+		links.Next = link(func(v url.Values) { v.Set("afterId", strconv.FormatUint(uint64(meta.NextAfterID), 10)) })
+	case meta.Page > 0:
+		if int64(meta.Page*meta.PageSize) < meta.Total {
+			links.Next = link(func(v url.Values) { v.Set("page", strconv.Itoa(meta.Page+1)) })
+		}
+		if meta.Page > 1 {
+			links.Prev = link(func(v url.Values) { v.Set("page", strconv.Itoa(meta.Page-1)) })
+		}
+	}
+	return links
+}
+
+// writeListResponse - Отдать список в конверте {data, meta, links}
+// или голым массивом при ?envelope=false (для старых клиентов).
+func writeListResponse(c *gin.Context, items interface{}, meta ListMeta) {
+	if c.Query("envelope") == "false" {
+		c.JSON(http.StatusOK, items)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": items, "meta": meta, "links": listLinks(c, meta)})
+}
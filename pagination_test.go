@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPageQueryOrdersByID - Любой порядок списка заканчивается id, поэтому страницы не пересекаются
+func TestPageQueryOrdersByID(t *testing.T) {
+	dry := dryRunDB(t)
+	createdAt, err := parseSort("-createdAt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		order listOrder
+		want  string
+	}{
+		{order: listOrder{}, want: "ORDER BY id LIMIT $1 OFFSET $2"},
+		{order: listOrder{SQL: createdAt}, want: "ORDER BY created_at DESC NULLS LAST, id LIMIT $1 OFFSET $2"},
+		{order: listOrder{SQL: createdAt}.withPrimary("pinned DESC"), want: "ORDER BY pinned DESC, created_at DESC NULLS LAST, id LIMIT $1 OFFSET $2"},
+	}
+	for _, tt := range tests {
+		var tasks []Task
+		sql := pageQuery(dry.Model(&Task{}), Pagination{Page: 2, PageSize: 3}, tt.order).Find(&tasks).Statement.SQL.String()
+		if !strings.HasSuffix(sql, tt.want) {
+			t.Errorf("page query = %s, want suffix %q", sql, tt.want)
+		}
+	}
+}
+
+// TestEqualSortKeysStableAcrossPages - Задачи с одинаковым ключом сортировки идут по id:
+// страницы покрывают все задачи ровно по разу, а повторный запрос дает тот же порядок
+func TestEqualSortKeysStableAcrossPages(t *testing.T) {
+	setupTestDB(t)
+	created := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	due := startOfDay(time.Now(), time.UTC).Add(12 * time.Hour)
+	var want []uint
+	for i := range 7 {
+		task := createTestTask(t, Task{Title: fmt.Sprintf("Задача %d", i), CreatedAt: created, DueDate: &due})
+		want = append(want, task.ID)
+	}
+
+	for _, sort := range []string{"createdAt", "-createdAt", "dueDate", "-priority"} {
+		var first []uint
+		for attempt := range 2 {
+			var got []uint
+			for page := 1; page <= 3; page++ {
+				w := performRequest(http.MethodGet, fmt.Sprintf("/tasks/?sort=%s&page=%d&pageSize=3", sort, page), "", "")
+				expectStatus(t, w, http.StatusOK)
+				got = append(got, responseTaskIDs(t, w)...)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("sort=%s: pages = %v, want %v", sort, got, want)
+			}
+			if attempt == 0 {
+				first = got
+			} else if !slices.Equal(got, first) {
+				t.Errorf("sort=%s: repeated request = %v, first = %v", sort, got, first)
+			}
+		}
+	}
+
+	w := performRequest(http.MethodGet, "/tasks/today?tz=UTC", "", "")
+	expectStatus(t, w, http.StatusOK)
+	if got := responseTaskIDs(t, w); !slices.Equal(got, want) {
+		t.Errorf("today = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- Закрепленные задачи ---
+
+// Сколько задач можно закрепить (MAX_PINNED_TASKS, 0 - без ограничения)
+// С заголовком пользователя считаются его задачи (автор или исполнитель), без него - все.
+var maxPinnedTasks = 5
+
+// Ставить ли закрепленные задачи первыми в списках по умолчанию (на запрос - ?pinnedFirst=)
+var pinnedFirstDefault = true
+
+// pinnedFirst - Поднимать ли закрепленные задачи наверх для этого запроса
+func pinnedFirst(c *gin.Context) bool {
+	if value, err := strconv.ParseBool(c.Query("pinnedFirst")); err == nil {
+		return value
+	}
+	return pinnedFirstDefault
+}
+
+// setPinned - Закрепить задачу или снять закрепление
+func setPinned(c *gin.Context, pinned bool) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	tx := txFromContext(c)
+	var task Task
+	if result := tx.First(&task, id); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+	if task.Pinned == pinned {
+		enrichTask(c, &task)
+		c.JSON(http.StatusOK, task)
+		return
+	}
+
+	if pinned && maxPinnedTasks > 0 {
+		var count int64
+		if err := scopeToUser(c, tx.Model(&Task{})).Where("pinned").Count(&count).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count pinned tasks"})
+			return
+		}
+		if count >= int64(maxPinnedTasks) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": fmt.Sprintf("You can pin at most %d tasks; unpin one first", maxPinnedTasks),
+				"code":  "pin_limit_reached",
+				"limit": maxPinnedTasks,
+			})
+			return
+		}
+	}
+
+	task.Pinned = pinned
+	if result := tx.Model(&task).Select("pinned", "updated_at", "last_activity_at").Updates(&task); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update task"})
+		return
+	}
+	enrichTask(c, &task)
+	c.JSON(http.StatusOK, task)
+}
+
+// PinTask - Закрепить задачу вверху списков (POST /tasks/:id/pin)
+func PinTask(c *gin.Context) {
+	setPinned(c, true)
+}
+
+// UnpinTask - Снять закрепление (POST /tasks/:id/unpin)
+func UnpinTask(c *gin.Context) {
+	setPinned(c, false)
+}
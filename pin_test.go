@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestPinnedTasksFirst - Закрепленная задача идет первой в списке, пока не передан ?pinnedFirst=false
+func TestPinnedTasksFirst(t *testing.T) {
+	setupTestDB(t)
+	first := createTestTask(t, Task{Title: "Первая"})
+	second := createTestTask(t, Task{Title: "Вторая"})
+
+	expectStatus(t, performRequest(http.MethodPost, fmt.Sprintf("/tasks/%d/pin", second.ID), "", ""), http.StatusOK)
+	for _, tt := range []struct {
+		query string
+		want  []uint
+	}{
+		{"/tasks/?sort=createdAt", []uint{second.ID, first.ID}},
+		{"/tasks/?sort=createdAt&pinnedFirst=false", []uint{first.ID, second.ID}},
+	} {
+		w := performRequest(http.MethodGet, tt.query, "", "")
+		expectStatus(t, w, http.StatusOK)
+		if got := responseTaskIDs(t, w); !slices.Equal(got, tt.want) {
+			t.Errorf("%s = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	expectStatus(t, performRequest(http.MethodPost, fmt.Sprintf("/tasks/%d/unpin", second.ID), "", ""), http.StatusOK)
+	w := performRequest(http.MethodGet, "/tasks/?sort=createdAt", "", "")
+	if got := responseTaskIDs(t, w); !slices.Equal(got, []uint{first.ID, second.ID}) {
+		t.Errorf("after unpin = %v, want creation order", got)
+	}
+}
+
+// TestPinLimit - Сверх MAX_PINNED_TASKS закрепить нельзя (409), считаются задачи пользователя
+func TestPinLimit(t *testing.T) {
+	setupTestDB(t)
+	saved := maxPinnedTasks
+	maxPinnedTasks = 1
+	t.Cleanup(func() { maxPinnedTasks = saved })
+	own := createTestTask(t, Task{Title: "Своя", CreatedBy: stringPtr("alice")})
+	another := createTestTask(t, Task{Title: "Еще своя", CreatedBy: stringPtr("alice")})
+	createTestTask(t, Task{Title: "Чужая закрепленная", CreatedBy: stringPtr("bob"), Pinned: true})
+
+	expectStatus(t, performRequest(http.MethodPost, fmt.Sprintf("/tasks/%d/pin", own.ID), "", "alice"), http.StatusOK)
+	w := performRequest(http.MethodPost, fmt.Sprintf("/tasks/%d/pin", another.ID), "", "alice")
+	expectStatus(t, w, http.StatusConflict)
+	if !strings.Contains(w.Body.String(), "pin_limit_reached") {
+		t.Errorf("over the limit: %s, want code pin_limit_reached", w.Body.String())
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- Приоритеты и локализация ---
+
+// Канонические значения приоритета, которые хранятся в БД
+const (
+	PriorityHigh   = "high"
+	PriorityMedium = "medium"
+	PriorityLow    = "low"
+)
+
+// priorityAliases - Допустимые входные значения приоритета (в любом регистре) и их каноническая форма
+var priorityAliases = map[string]string{
+	"high": PriorityHigh, "высокий": PriorityHigh,
+	"medium": PriorityMedium, "средний": PriorityMedium,
+	"low": PriorityLow, "низкий": PriorityLow,
+}
+
+// priorityLabels - Отображаемые названия приоритетов по локалям
+var priorityLabels = map[string]map[string]string{
+	"ru": {PriorityHigh: "высокий", PriorityMedium: "средний", PriorityLow: "низкий"},
+	"en": {PriorityHigh: "High", PriorityMedium: "Medium", PriorityLow: "Low"},
+}
+
+// priorityOrder - Приоритеты от высокого к низкому (для GET /priorities)
+var priorityOrder = []string{PriorityHigh, PriorityMedium, PriorityLow}
+
+// priorityColors - Цвета приоритетов, общие для всех клиентов (PRIORITY_COLORS)
+var priorityColors = map[string]string{
+	PriorityHigh:   "#e53935",
+	PriorityMedium: "#fb8c00",
+	PriorityLow:    "#43a047",
+}
+
+// parsePriorityColors - Разобрать PRIORITY_COLORS вида "high=#d32f2f,low=#388e3c" поверх цветов по умолчанию
+// Приоритет можно указать и по-русски; неизвестный приоритет или цвет - ошибка конфигурации.
+func parsePriorityColors(raw string, defaults map[string]string) (map[string]string, error) {
+	colors := make(map[string]string, len(defaults))
+	for priority, color := range defaults {
+		colors[priority] = color
+	}
+	for _, part := range strings.Split(raw, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		name, color, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid entry %q, expected priority=#color", part)
+		}
+		priority, err := normalizePriority(name)
+		if err != nil || priority == "" {
+			return nil, fmt.Errorf("unknown priority %q", strings.TrimSpace(name))
+		}
+		color = strings.ToLower(strings.TrimSpace(color))
+		if !hexColorPattern.MatchString(color) {
+			return nil, fmt.Errorf("invalid color %q for priority %s, expected #rgb or #rrggbb", color, priority)
+		}
+		colors[priority] = color
+	}
+	return colors, nil
+}
+
+// Локаль ответов по умолчанию
+const defaultLocale = "ru"
+
+// normalizePriority - Привести приоритет к каноническому значению; пустая строка - приоритет не задан
+func normalizePriority(raw string) (string, error) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if raw == "" {
+		return "", nil
+	}
+	if canonical, ok := priorityAliases[raw]; ok {
+		return canonical, nil
+	}
+	return "", fmt.Errorf("invalid priority %q, expected one of high, medium, low (or высокий, средний, низкий)", raw)
+}
+
+// requestLocale - Локаль ответа: ?locale=, затем первый поддерживаемый язык из Accept-Language
+func requestLocale(c *gin.Context) string {
+	if locale := strings.ToLower(c.Query("locale")); priorityLabels[locale] != nil {
+		return locale
+	}
+	for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if priorityLabels[lang] != nil {
+			return lang
+		}
+	}
+	return defaultLocale
+}
+
+// localizePriorities - Заполнить priorityLabel на языке запроса и priorityColor
+func localizePriorities(c *gin.Context, tasks []Task) {
+	labels := priorityLabels[requestLocale(c)]
+	for i := range tasks {
+		tasks[i].PriorityLabel = labels[tasks[i].Priority]
+		tasks[i].PriorityColor = priorityColors[tasks[i].Priority]
+	}
+}
+
+// GetPriorities - Допустимые приоритеты с названием на языке запроса и цветом (GET /priorities)
+func GetPriorities(c *gin.Context) {
+	labels := priorityLabels[requestLocale(c)]
+	priorities := make([]gin.H, len(priorityOrder))
+	for i, priority := range priorityOrder {
+		priorities[i] = gin.H{"value": priority, "label": labels[priority], "color": priorityColors[priority]}
+	}
+	c.JSON(http.StatusOK, priorities)
+}
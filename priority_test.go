@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParsePriorityColors(t *testing.T) {
+	defaults := map[string]string{PriorityHigh: "#e53935", PriorityMedium: "#fb8c00", PriorityLow: "#43a047"}
+	tests := []struct {
+		raw     string
+		want    map[string]string
+		wantErr bool
+	}{
+		{raw: "", want: defaults},
+		{raw: "high=#D32F2F, низкий = #abc", want: map[string]string{PriorityHigh: "#d32f2f", PriorityMedium: "#fb8c00", PriorityLow: "#abc"}},
+		{raw: "high=#d32f2f,", want: map[string]string{PriorityHigh: "#d32f2f", PriorityMedium: "#fb8c00", PriorityLow: "#43a047"}},
+		{raw: "urgent=#ff0000", wantErr: true},
+		{raw: "=#ff0000", wantErr: true},
+		{raw: "high=red", wantErr: true},
+		{raw: "high", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parsePriorityColors(tt.raw, defaults)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parsePriorityColors(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !maps.Equal(got, tt.want) {
+			t.Errorf("parsePriorityColors(%q) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+	if defaults[PriorityHigh] != "#e53935" {
+		t.Errorf("parsePriorityColors changed the defaults: %v", defaults)
+	}
+}
+
+// TestGetPriorities - GET /priorities отдает приоритеты по порядку с названием на языке запроса и цветом
+func TestGetPriorities(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/priorities", nil)
+	c.Request.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	GetPriorities(c)
+
+	expectStatus(t, w, http.StatusOK)
+	var got []struct {
+		Value, Label, Color string
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(priorityOrder) {
+		t.Fatalf("priorities = %+v, want %d entries", got, len(priorityOrder))
+	}
+	for i, priority := range priorityOrder {
+		if got[i].Value != priority || got[i].Label != priorityLabels["en"][priority] || got[i].Color != priorityColors[priority] {
+			t.Errorf("priority %d = %+v, want %s with its English label and color", i, got[i], priority)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// --- Тихие часы для напоминаний ---
+
+// quietHours - Окно времени суток, когда напоминания не отправляются
+// Минуты от полуночи; start > end - окно переходит через полночь (22:00-07:00).
+type quietHours struct {
+	start, end int
+}
+
+// Тихие часы по умолчанию для всех пользователей (QUIET_HOURS, например 22:00-07:00; nil - нет)
+var defaultQuietHours *quietHours
+
+// quietHoursOff - Значение настройки пользователя, отключающее и тихие часы по умолчанию
+const quietHoursOff = "off"
+
+// parseQuietHours - Разобрать окно вида "22:00-07:00"; пустая строка - тихих часов нет
+func parseQuietHours(raw string) (*quietHours, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	from, to, ok := strings.Cut(raw, "-")
+	if !ok {
+		return nil, fmt.Errorf("invalid quiet hours %q, expected HH:MM-HH:MM", raw)
+	}
+	startHour, startMinute, err := parseTimeOfDay(strings.ToLower(from))
+	if err != nil {
+		return nil, err
+	}
+	endHour, endMinute, err := parseTimeOfDay(strings.ToLower(to))
+	if err != nil {
+		return nil, err
+	}
+	q := &quietHours{start: startHour*60 + startMinute, end: endHour*60 + endMinute}
+	if q.start == q.end {
+		return nil, fmt.Errorf("invalid quiet hours %q: start and end must differ", raw)
+	}
+	return q, nil
+}
+
+// normalizeQuietHoursSetting - Проверить значение quietHours из настроек пользователя и привести к виду "22:00-07:00"
+func normalizeQuietHoursSetting(raw string) (string, error) {
+	if strings.EqualFold(strings.TrimSpace(raw), quietHoursOff) {
+		return quietHoursOff, nil
+	}
+	q, err := parseQuietHours(raw)
+	if err != nil || q == nil {
+		return "", err
+	}
+	return q.String(), nil
+}
+
+// recipientQuietHours - Тихие часы получателя напоминания и пояс, в котором они считаются
+// Настройка пользователя важнее QUIET_HOURS; без пояса в настройках - пояс сервера.
+func recipientQuietHours(settings UserSettings) (*quietHours, *time.Location) {
+	loc := time.Local
+	if settings.Timezone != "" {
+		if userLoc, err := time.LoadLocation(settings.Timezone); err == nil {
+			loc = userLoc
+		}
+	}
+	switch settings.QuietHours {
+	case "":
+		return defaultQuietHours, loc
+	case quietHoursOff:
+		return nil, loc
+	}
+	q, err := parseQuietHours(settings.QuietHours)
+	if err != nil {
+		log.Printf("Stored quiet hours %q of user %s are invalid: %v", settings.QuietHours, settings.UserID, err)
+		return defaultQuietHours, loc
+	}
+	return q, loc
+}
+
+// String - Окно в каноническом виде "22:00-07:00"
+func (q quietHours) String() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", q.start/60, q.start%60, q.end/60, q.end%60)
+}
+
+// contains - Попадает ли момент t (по часам пояса loc) в тихие часы
+func (q quietHours) contains(t time.Time, loc *time.Location) bool {
+	t = t.In(loc)
+	minute := t.Hour()*60 + t.Minute()
+	if q.start < q.end {
+		return minute >= q.start && minute < q.end
+	}
+	return minute >= q.start || minute < q.end
+}
+
+// nextAllowed - Ближайший момент не раньше t вне тихих часов (конец окна, если t внутри)
+// Окно через полночь, начавшееся вечером, заканчивается на следующий день.
+func (q quietHours) nextAllowed(t time.Time, loc *time.Location) time.Time {
+	if !q.contains(t, loc) {
+		return t
+	}
+	local := t.In(loc)
+	end := time.Date(local.Year(), local.Month(), local.Day(), q.end/60, q.end%60, 0, 0, loc)
+	if !end.After(t) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseQuietHours(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{raw: "22:00-07:00", want: "22:00-07:00"},
+		{raw: " 9:30 - 18:00 ", want: "09:30-18:00"},
+		{raw: "10PM-7AM", want: "22:00-07:00"},
+		{raw: "22:00", wantErr: true},
+		{raw: "22:00-22:00", wantErr: true},
+		{raw: "25:00-07:00", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseQuietHours(tt.raw)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseQuietHours(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got.String() != tt.want {
+			t.Errorf("parseQuietHours(%q) = %s, want %s", tt.raw, got, tt.want)
+		}
+	}
+	if got, err := parseQuietHours(""); got != nil || err != nil {
+		t.Errorf("parseQuietHours(\"\") = %v, %v; want no quiet hours", got, err)
+	}
+}
+
+// TestQuietHoursAcrossMidnight - Окно 22:00-07:00 закрывает и вечер, и утро,
+// а отложенное напоминание уходит в конец окна на следующий день
+func TestQuietHoursAcrossMidnight(t *testing.T) {
+	loc := time.FixedZone("UTC+5", 5*60*60)
+	q, err := parseQuietHours("22:00-07:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(day, hour, minute int) time.Time { return time.Date(2026, 3, day, hour, minute, 0, 0, loc) }
+	tests := []struct {
+		t         time.Time
+		contains  bool
+		nextAllow time.Time
+	}{
+		{t: at(10, 21, 59), contains: false, nextAllow: at(10, 21, 59)},
+		{t: at(10, 22, 0), contains: true, nextAllow: at(11, 7, 0)},
+		{t: at(11, 3, 15), contains: true, nextAllow: at(11, 7, 0)},
+		{t: at(11, 7, 0), contains: false, nextAllow: at(11, 7, 0)},
+	}
+	for _, tt := range tests {
+		// Момент передается в UTC: окно считается по часам пояса получателя
+		utc := tt.t.UTC()
+		if got := q.contains(utc, loc); got != tt.contains {
+			t.Errorf("contains(%v) = %v, want %v", tt.t, got, tt.contains)
+		}
+		if got := q.nextAllowed(utc, loc); !got.Equal(tt.nextAllow) {
+			t.Errorf("nextAllowed(%v) = %v, want %v", tt.t, got, tt.nextAllow)
+		}
+	}
+
+	day := quietHours{start: 13 * 60, end: 14 * 60}
+	if !day.contains(at(10, 13, 30), loc) || day.contains(at(10, 14, 0), loc) {
+		t.Errorf("daytime window 13:00-14:00 boundaries are wrong")
+	}
+}
+
+func TestNormalizeQuietHoursSetting(t *testing.T) {
+	for raw, want := range map[string]string{"OFF": quietHoursOff, "": "", "23:00-6:30": "23:00-06:30"} {
+		if got, err := normalizeQuietHoursSetting(raw); err != nil || got != want {
+			t.Errorf("normalizeQuietHoursSetting(%q) = %q, %v; want %q", raw, got, err, want)
+		}
+	}
+	if _, err := normalizeQuietHoursSetting("always"); err == nil {
+		t.Error("normalizeQuietHoursSetting(\"always\"): want an error")
+	}
+}
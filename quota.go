@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// --- Ограничение числа задач на пользователя ---
+
+// Максимум задач на пользователя (MAX_TASKS_PER_USER), 0 - без ограничения
+var maxTasksPerUser = 0
+
+// Учитывать ли в лимите удаленные (в корзине) и архивные задачи
+// (TASK_LIMIT_COUNT_DELETED, TASK_LIMIT_COUNT_ARCHIVED)
+var taskLimitCountDeleted, taskLimitCountArchived = false, false
+
+// userTaskCount - Число задач, созданных пользователем, с учетом настроек лимита
+func userTaskCount(tx *gorm.DB, userID string) (int64, error) {
+	query := tx.Model(&Task{})
+	if taskLimitCountDeleted {
+		query = query.Unscoped()
+	}
+	query = query.Where("created_by = ?", userID)
+	if !taskLimitCountArchived {
+		query = query.Where("archived_at IS NULL")
+	}
+	var count int64
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// checkTaskLimit - Проверить, что текущий пользователь может создать еще adding задач
+// Анонимные запросы не ограничиваются. При отказе ответ уже отправлен и возвращается false.
+func checkTaskLimit(c *gin.Context, adding int) bool {
+	userID := currentUserID(c)
+	if maxTasksPerUser <= 0 || userID == nil {
+		return true
+	}
+	count, err := userTaskCount(txFromContext(c), *userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count tasks"})
+		return false
+	}
+	if count+int64(adding) > int64(maxTasksPerUser) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": fmt.Sprintf("Task limit reached: you have %d of %d tasks", count, maxTasksPerUser),
+			"code":  "task_limit_reached",
+			"count": count,
+			"limit": maxTasksPerUser,
+		})
+		return false
+	}
+	return true
+}
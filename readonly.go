@@ -0,0 +1,66 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- Режим только для чтения ---
+
+// readOnly - Запрет изменений на время обслуживания (READ_ONLY=true или PUT /admin/read-only)
+var readOnly atomic.Bool
+
+// readOnlyPath - Переключатель режима доступен и в режиме только для чтения, иначе его нельзя выключить
+const readOnlyPath = "/admin/read-only"
+
+// readOnlySafeRoutes - POST-маршруты, которые только читают данные (запрос передается в теле)
+var readOnlySafeRoutes = map[string]bool{
+	readOnlyPath:         true,
+	"/tasks/query":       true,
+	"/tasks/stats/batch": true,
+	"/ai/query":          true,
+}
+
+// readOnlyGuard - В режиме только для чтения отвечает 503 на все методы, кроме GET, HEAD и OPTIONS
+// (и маршрутов из readOnlySafeRoutes). Фоновые задачи в этом режиме тоже пропускают запуски.
+func readOnlyGuard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if readOnly.Load() && !readOnlySafeRoutes[c.FullPath()] {
+			c.Header("Retry-After", "60")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"error": "The API is in read-only mode for maintenance, changes are temporarily disabled",
+				"code":  "read_only",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// GetReadOnly - Текущее состояние режима (GET /admin/read-only)
+func GetReadOnly(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"readOnly": readOnly.Load()})
+}
+
+// SetReadOnly - Включить или выключить режим без перезапуска (PUT /admin/read-only)
+// Тело: {"enabled": true}. Состояние не сохраняется: после перезапуска снова действует READ_ONLY.
+func SetReadOnly(c *gin.Context) {
+	var requestBody struct {
+		Enabled *bool `json:"enabled" binding:"required"`
+	}
+	if err := bindJSON(c, &requestBody); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	readOnly.Store(*requestBody.Enabled)
+	log.Printf("Read-only mode set to %v (request %s)", *requestBody.Enabled, requestIDFrom(c))
+	c.JSON(http.StatusOK, gin.H{"readOnly": *requestBody.Enabled})
+}
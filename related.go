@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// --- Связанные задачи ---
+
+// Размер каждой группы связанных задач по умолчанию (RELATED_LIMIT) и максимальный для ?limit=
+var relatedLimit = 5
+
+const maxRelatedLimit = 50
+
+// relatedQuery - Выборка связанных задач: без самой задачи, самые активные первыми
+func relatedQuery(tx *gorm.DB, task Task, limit int) *gorm.DB {
+	return tx.Model(&Task{}).Where("id <> ?", task.ID).Order("last_activity_at DESC, id").Limit(limit)
+}
+
+// GetRelatedTasks - Задачи, связанные с данной (GET /tasks/:id/related?limit=)
+// Группы: byTag - с общими тегами, byProject - то же дерево подзадач (родитель, соседние
+// задачи и подзадачи), dependsOn и blocks - зависимости в обе стороны.
+func GetRelatedTasks(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	limit := relatedLimit
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxRelatedLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", maxRelatedLimit)})
+			return
+		}
+		limit = n
+	}
+	tx := txFromContext(c)
+	var task Task
+	if result := tx.First(&task, id); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	byTag := []Task{}
+	if tags := splitTags(task.Tags); len(tags) > 0 {
+		for i := range tags {
+			tags[i] = strings.ToLower(tags[i])
+		}
+		relatedQuery(tx, task, limit).
+			Where("EXISTS (SELECT 1 FROM unnest(string_to_array(tags, ',')) AS tag WHERE lower(trim(tag)) IN ?)", tags).
+			Find(&byTag)
+	}
+
+	byProject := []Task{}
+	tree := relatedQuery(tx, task, limit).Where("parent_id = ?", task.ID)
+	if task.ParentID != nil {
+		tree = relatedQuery(tx, task, limit).Where("id = ? OR parent_id = ? OR parent_id = ?", *task.ParentID, *task.ParentID, task.ID)
+	}
+	tree.Find(&byProject)
+
+	dependsOn, blocks := []Task{}, []Task{}
+	relatedQuery(tx, task, limit).
+		Where("id IN (?)", tx.Model(&TaskDependency{}).Select("depends_on_id").Where("task_id = ?", task.ID)).
+		Find(&dependsOn)
+	relatedQuery(tx, task, limit).
+		Where("id IN (?)", tx.Model(&TaskDependency{}).Select("task_id").Where("depends_on_id = ?", task.ID)).
+		Find(&blocks)
+
+	for _, group := range [][]Task{byTag, byProject, dependsOn, blocks} {
+		enrichTasks(c, group)
+		for i := range group {
+			group[i].toListView()
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"byTag":     byTag,
+		"byProject": byProject,
+		"dependsOn": dependsOn,
+		"blocks":    blocks,
+	})
+}
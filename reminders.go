@@ -0,0 +1,270 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- Напоминания по задачам ---
+
+// Reminder - Напоминание о сроке задачи. Задается одним из способов:
+// beforeMinutes - за N минут до срока ("за час"), atTime + daysBefore - в указанное время
+// за daysBefore дней до дня срока ("в 9:00 в день срока").
+type Reminder struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	TaskID        uint       `json:"taskId" gorm:"index"`
+	BeforeMinutes *int       `json:"beforeMinutes,omitempty"`
+	AtTime        *string    `json:"atTime,omitempty"` // "09:00"
+	DaysBefore    int        `json:"daysBefore"`
+	FiredFor      *time.Time `json:"firedFor"` // Срок, для которого напоминание уже сработало
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+// Максимум напоминаний на задачу
+const maxRemindersPerTask = 10
+
+// fireTime - Момент срабатывания напоминания для срока due (в часовом поясе сервера)
+func (r Reminder) fireTime(due time.Time) time.Time {
+	if r.BeforeMinutes != nil {
+		return due.Add(-time.Duration(*r.BeforeMinutes) * time.Minute)
+	}
+	hour, minute, _ := parseTimeOfDay(*r.AtTime)
+	local := due.In(time.Local)
+	day := local.AddDate(0, 0, -r.DaysBefore)
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, time.Local)
+}
+
+// reminderInput - Тело запроса на создание напоминания: {"before": "1h"} или {"at": "9am", "daysBefore": 0}
+type reminderInput struct {
+	Before     string `json:"before"`
+	At         string `json:"at"`
+	DaysBefore int    `json:"daysBefore"`
+}
+
+// toReminder - Проверить тело запроса и построить напоминание
+func (in reminderInput) toReminder(taskID uint) (Reminder, error) {
+	reminder := Reminder{TaskID: taskID}
+	before, at := strings.TrimSpace(in.Before), strings.TrimSpace(in.At)
+	switch {
+	case (before == "") == (at == ""):
+		return reminder, errors.New("exactly one of before or at is required")
+	case before != "":
+		offset, err := parseRelativeDuration(before)
+		if err != nil || offset < 0 || offset%time.Minute != 0 {
+			return reminder, fmt.Errorf("invalid before value %q, expected a non-negative whole number of minutes like 30m, 1h or 2d", before)
+		}
+		minutes := int(offset / time.Minute)
+		reminder.BeforeMinutes = &minutes
+		if in.DaysBefore != 0 {
+			return reminder, errors.New("daysBefore can only be combined with at")
+		}
+	default:
+		hour, minute, err := parseTimeOfDay(strings.ToLower(at))
+		if err != nil {
+			return reminder, err
+		}
+		if in.DaysBefore < 0 || in.DaysBefore > 365 {
+			return reminder, errors.New("daysBefore must be between 0 and 365")
+		}
+		atTime := fmt.Sprintf("%02d:%02d", hour, minute)
+		reminder.AtTime = &atTime
+		reminder.DaysBefore = in.DaysBefore
+	}
+	return reminder, nil
+}
+
+// GetReminders - Напоминания задачи (GET /tasks/:id/reminders)
+func GetReminders(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	tx := txFromContext(c)
+	var task Task
+	if result := tx.First(&task, id); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+	reminders := []Reminder{}
+	tx.Where("task_id = ?", task.ID).Order("id").Find(&reminders)
+	c.JSON(http.StatusOK, reminders)
+}
+
+// AddReminder - Добавить напоминание (POST /tasks/:id/reminders)
+func AddReminder(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	tx := txFromContext(c)
+	var task Task
+	if result := tx.First(&task, id); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	var input reminderInput
+	if err := bindJSON(c, &input); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	reminder, err := input.toReminder(task.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var count int64
+	tx.Model(&Reminder{}).Where("task_id = ?", task.ID).Count(&count)
+	if count >= maxRemindersPerTask {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("a task can have at most %d reminders", maxRemindersPerTask)})
+		return
+	}
+	if result := tx.Create(&reminder); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add reminder"})
+		return
+	}
+	c.JSON(http.StatusCreated, reminder)
+}
+
+// DeleteReminder - Удалить одно напоминание (DELETE /tasks/:id/reminders/:reminderId)
+func DeleteReminder(c *gin.Context) {
+	taskID, ok := parseID(c)
+	if !ok {
+		return
+	}
+	reminderID, err := strconv.ParseUint(c.Param("reminderId"), 10, 64)
+	if err != nil || reminderID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reminder id", "code": "invalid_id"})
+		return
+	}
+	if result := txFromContext(c).Where("task_id = ?", taskID).Delete(&Reminder{}, reminderID); result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Reminder not found"})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ClearReminders - Удалить все напоминания задачи (DELETE /tasks/:id/reminders)
+func ClearReminders(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	result := txFromContext(c).Where("task_id = ?", id).Delete(&Reminder{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear reminders"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deleted": result.RowsAffected})
+}
+
+// dueReminder - Напоминание вместе с полями задачи, нужными планировщику
+type dueReminder struct {
+	Reminder
+	Title     string
+	DueDate   time.Time
+	Recipient *string // Исполнитель, а если его нет - автор задачи
+}
+
+// Сколько после конца тихих часов еще отправляется отложенное напоминание, чей срок уже прошел
+// (например, после простоя планировщика); позже оно помечается сработавшим без отправки
+const quietHoursGrace = 24 * time.Hour
+
+// recipientSettings - Настройки получателей напоминаний одним запросом
+func recipientSettings(candidates []dueReminder) (map[string]UserSettings, error) {
+	var ids []string
+	for _, candidate := range candidates {
+		if candidate.Recipient != nil {
+			ids = append(ids, *candidate.Recipient)
+		}
+	}
+	settings := make(map[string]UserSettings)
+	if len(ids) == 0 {
+		return settings, nil
+	}
+	var rows []UserSettings
+	if err := db.Where("user_id IN ?", ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		settings[row.UserID] = row
+	}
+	return settings, nil
+}
+
+// fireDueReminders - Отправить напоминания, время которых наступило
+// Напоминание срабатывает один раз на каждый срок: при переносе срока оно сработает снова.
+// Если срок уже прошел, напоминание помечается сработавшим без отправки.
+// В тихие часы получателя напоминание откладывается до их конца и не теряется,
+// даже если срок успел пройти за ночь.
+func fireDueReminders(now time.Time) (int, error) {
+	var candidates []dueReminder
+	err := db.Table("reminders").
+		Select("reminders.*, tasks.title, tasks.due_date, COALESCE(tasks.assignee_id, tasks.created_by) AS recipient").
+		Joins("JOIN tasks ON tasks.id = reminders.task_id").
+		Where("tasks.deleted_at IS NULL AND tasks.archived_at IS NULL AND NOT tasks.is_completed AND tasks.due_date IS NOT NULL").
+		Where("reminders.fired_for IS NULL OR reminders.fired_for <> tasks.due_date").
+		Scan(&candidates).Error
+	if err != nil {
+		return 0, err
+	}
+
+	settings, err := recipientSettings(candidates)
+	if err != nil {
+		return 0, err
+	}
+
+	fired := 0
+	for _, candidate := range candidates {
+		fireAt := candidate.fireTime(candidate.DueDate)
+		if fireAt.After(now) {
+			continue
+		}
+		var recipient UserSettings
+		if candidate.Recipient != nil {
+			recipient = settings[*candidate.Recipient]
+		}
+		quiet, loc := recipientQuietHours(recipient)
+		if quiet != nil && quiet.contains(now, loc) {
+			continue // Отложено до конца тихих часов
+		}
+		held := quiet != nil && quiet.contains(fireAt, loc) && candidate.DueDate.After(fireAt) &&
+			now.Before(quiet.nextAllowed(fireAt, loc).Add(quietHoursGrace))
+		if candidate.DueDate.After(now) || held {
+			deliverReminder(candidate)
+			fired++
+		}
+		if err := db.Model(&Reminder{}).Where("id = ?", candidate.ID).
+			UpdateColumn("fired_for", candidate.DueDate).Error; err != nil {
+			return fired, err
+		}
+	}
+	return fired, nil
+}
+
+// deliverReminder - Отправить напоминание (пока только в лог сервера)
+func deliverReminder(r dueReminder) {
+	log.Printf("Reminder %d: task %d %q is due at %s", r.ID, r.TaskID, r.Title, r.DueDate.Format(time.RFC3339))
+}
+
+// runReminderScheduler - Периодически проверять напоминания (интервал REMINDER_INTERVAL)
+func runReminderScheduler() {
+	ticker := time.NewTicker(getEnvDuration("REMINDER_INTERVAL", time.Minute))
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		if readOnly.Load() {
+			continue
+		}
+		if _, err := fireDueReminders(now); err != nil {
+			log.Printf("Reminder scheduler failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// --- Задачи, требующие разбора ---
+
+// reviewCriterion - Признак незаполненного поля: условие в SQL и та же проверка для загруженной задачи
+type reviewCriterion struct {
+	sql     string
+	missing func(t *Task) bool
+}
+
+// reviewCriteria - Поля, отсутствие которых можно считать поводом разобрать задачу
+var reviewCriteria = map[string]reviewCriterion{
+	"dueDate":     {"due_date IS NULL", func(t *Task) bool { return t.DueDate == nil }},
+	"priority":    {"priority = ''", func(t *Task) bool { return t.Priority == "" }},
+	"description": {"description = ''", func(t *Task) bool { return strings.TrimSpace(t.Description) == "" }},
+	"tags":        {"tags = ''", func(t *Task) bool { return strings.TrimSpace(t.Tags) == "" }},
+	"assignee":    {"assignee_id IS NULL", func(t *Task) bool { return t.AssigneeID == nil }},
+}
+
+// reviewFields - Критерии, включенные через NEEDS_REVIEW_FIELDS (по умолчанию срок и приоритет)
+var reviewFields = []string{"dueDate", "priority"}
+
+// parseReviewFields - Разобрать NEEDS_REVIEW_FIELDS ("dueDate,priority"); пустая строка отключает признак
+func parseReviewFields(raw string) ([]string, error) {
+	fields := []string{}
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := reviewCriteria[name]; !ok {
+			return nil, fmt.Errorf("unknown field %q, expected dueDate, priority, description, tags or assignee", name)
+		}
+		fields = append(fields, name)
+	}
+	return fields, nil
+}
+
+// needsReview - Незавершенная задача, у которой не заполнено хотя бы одно из полей reviewFields
+func (t *Task) needsReview() bool {
+	if t.IsCompleted {
+		return false
+	}
+	for _, name := range reviewFields {
+		if reviewCriteria[name].missing(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// markNeedsReview - Заполнить вычисляемое поле needsReview
+func markNeedsReview(tasks []Task) {
+	for i := range tasks {
+		tasks[i].NeedsReview = tasks[i].needsReview()
+	}
+}
+
+// applyNeedsReview - Условие ?needsReview=true|false, совпадающее с needsReview()
+func applyNeedsReview(query *gorm.DB, needsReview bool) *gorm.DB {
+	condition := "FALSE"
+	if len(reviewFields) > 0 {
+		parts := make([]string, len(reviewFields))
+		for i, name := range reviewFields {
+			parts[i] = reviewCriteria[name].sql
+		}
+		condition = "NOT is_completed AND (" + strings.Join(parts, " OR ") + ")"
+	}
+	if !needsReview {
+		condition = "NOT (" + condition + ")"
+	}
+	return query.Where(condition)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+// --- Полнотекстовый поиск ---
+
+// Конфигурация текстового поиска Postgres. "simple" не применяет стемминг,
+// зато одинаково работает для русских и английских задач.
+const searchConfig = "simple"
+
+// SearchTasks - Полнотекстовый поиск по названию и описанию (GET /tasks/search?q=)
+// Использует колонку search_vector с GIN-индексом; результаты отсортированы по ts_rank.
+// Запрос в синтаксисе websearch: "купить молоко", -хлеб, "точная фраза", or.
+func SearchTasks(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter q is required"})
+		return
+	}
+	tsQuery := clause.Expr{SQL: "websearch_to_tsquery(?, ?)", Vars: []interface{}{searchConfig, q}}
+	query := txFromContext(c).Model(&Task{}).Where("search_vector @@ ?", tsQuery)
+	tasks, meta, ok := paginate(c, query, listOrder{SQL: "ts_rank(search_vector, ?) DESC", Vars: []interface{}{tsQuery}})
+	if !ok {
+		return
+	}
+	writeListResponse(c, tasks, meta)
+}
+
+// Максимум подсказок автодополнения
+const maxAutocompleteResults = 10
+
+// TaskSuggestion - Подсказка автодополнения: только id и название
+type TaskSuggestion struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+}
+
+// autocompleteTasks - Задачи, название которых начинается с prefix (GET /tasks?titlePrefix=)
+// Сравнение lower(title) LIKE 'prefix%' использует индекс idx_tasks_title_prefix, в отличие от ILIKE.
+// Остальные фильтры GET /tasks применяются как обычно; ?limit= - не больше maxAutocompleteResults.
+func autocompleteTasks(c *gin.Context, filter TaskFilter, prefix string) {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "titlePrefix must not be empty"})
+		return
+	}
+	limit := maxAutocompleteResults
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxAutocompleteResults {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", maxAutocompleteResults)})
+			return
+		}
+		limit = n
+	}
+	if err := resolveAssignee(c, &filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	suggestions := []TaskSuggestion{}
+	result := applyTaskFilter(txFromContext(c).Model(&Task{}), filter).
+		Where("lower(title) LIKE ?", strings.ToLower(escapeLike(prefix))+"%").
+		Order("lower(title), id").Limit(limit).
+		Find(&suggestions)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load suggestions"})
+		return
+	}
+	c.JSON(http.StatusOK, suggestions)
+}
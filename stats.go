@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// --- Статистика по задачам ---
+
+// TaskStats - Сводные показатели по задачам
+type TaskStats struct {
+	Total      int64            `json:"total"`
+	Completed  int64            `json:"completed"`
+	Pending    int64            `json:"pending"`
+	Overdue    int64            `json:"overdue"`
+	ByPriority map[string]int64 `json:"byPriority"`
+}
+
+// computeTaskStats - Посчитать статистику агрегирующими запросами
+func computeTaskStats(tx *gorm.DB) (*TaskStats, error) {
+	stats := &TaskStats{ByPriority: make(map[string]int64)}
+
+	var totals struct {
+		Total     int64
+		Completed int64
+		Overdue   int64
+	}
+	err := tx.Model(&Task{}).
+		Select(`COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE is_completed) AS completed,
+			COUNT(*) FILTER (WHERE NOT is_completed AND due_date < ?) AS overdue`, time.Now()).
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	stats.Total = totals.Total
+	stats.Completed = totals.Completed
+	stats.Pending = totals.Total - totals.Completed
+	stats.Overdue = totals.Overdue
+
+	var rows []struct {
+		Priority string
+		Count    int64
+	}
+	if err := tx.Model(&Task{}).Select("priority, COUNT(*) AS count").Group("priority").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		stats.ByPriority[row.Priority] = row.Count
+	}
+	return stats, nil
+}
+
+// Время жизни закэшированной статистики (STATS_CACHE_TTL)
+var statsCacheTTL = 30 * time.Second
+
+// statsCache - Кэш статистики в памяти
+// Пересчет выполняется под отдельной блокировкой, поэтому при истечении TTL
+// в базу идет только один запрос, а остальные ждут его результат.
+type statsCache struct {
+	mu         sync.RWMutex
+	value      *TaskStats
+	expires    time.Time
+	generation uint64 // Растет при каждой инвалидации
+
+	computeMu sync.Mutex
+}
+
+var taskStatsCache = &statsCache{}
+
+// cached - Актуальное значение из кэша, если оно есть
+func (sc *statsCache) cached() (*TaskStats, uint64, bool) {
+	sc.mu.RLock()
+	defer sc.mu.RUnlock()
+	if sc.value != nil && time.Now().Before(sc.expires) {
+		return sc.value, sc.generation, true
+	}
+	return nil, sc.generation, false
+}
+
+// get - Получить статистику из кэша или пересчитать ее
+func (sc *statsCache) get(tx *gorm.DB) (*TaskStats, bool, error) {
+	if stats, _, ok := sc.cached(); ok {
+		return stats, true, nil
+	}
+
+	sc.computeMu.Lock()
+	defer sc.computeMu.Unlock()
+
+	// Пока ждали блокировку, значение мог посчитать другой запрос
+	stats, generation, ok := sc.cached()
+	if ok {
+		return stats, true, nil
+	}
+
+	stats, err := computeTaskStats(tx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	sc.mu.Lock()
+	// Если задачи менялись во время пересчета, результат мог устареть - не кэшируем
+	if sc.generation == generation {
+		sc.value = stats
+		sc.expires = time.Now().Add(statsCacheTTL)
+	}
+	sc.mu.Unlock()
+	return stats, false, nil
+}
+
+// invalidate - Сбросить кэш (вызывается из хуков задач)
+func (sc *statsCache) invalidate() {
+	sc.mu.Lock()
+	sc.value = nil
+	sc.generation++
+	sc.mu.Unlock()
+}
+
+// GetTaskStats - Сводная статистика по задачам (кэшируется на statsCacheTTL)
+func GetTaskStats(c *gin.Context) {
+	stats, hit, err := taskStatsCache.get(txFromContext(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute stats"})
+		return
+	}
+	if hit {
+		c.Header("X-Cache", "HIT")
+	} else {
+		c.Header("X-Cache", "MISS")
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// filteredCount - COUNT(*) FILTER (WHERE <условия фильтра>) AS alias для одного фильтра из пакета
+type filteredCount struct {
+	where clause.Where
+	alias string
+}
+
+func (f filteredCount) Build(builder clause.Builder) {
+	builder.WriteString("COUNT(*) FILTER (WHERE ")
+	if len(f.where.Exprs) == 0 {
+		builder.WriteString("TRUE")
+	} else {
+		f.where.Build(builder)
+	}
+	builder.WriteString(") AS ")
+	builder.WriteQuoted(f.alias)
+}
+
+// filterConditions - Условия фильтра в виде WHERE-выражения, пригодного для вставки в агрегат
+func filterConditions(filter TaskFilter) clause.Where {
+	query := applyTaskFilter(db.Session(&gorm.Session{NewDB: true}), filter)
+	where, _ := query.Statement.Clauses["WHERE"].Expression.(clause.Where)
+	return where
+}
+
+// GetBatchStats - Количество задач для нескольких именованных фильтров одним запросом (POST /tasks/stats/batch)
+// Тело: {"filters": [{"name": "overdue", "query": "filter=overdue"}, {"name": "high", "query": "priority=high&completed=false"}]}.
+// query - параметры в том же формате, что у GET /tasks, и разбирается тем же parseTaskFilter.
+// Фильтров - от 1 до 20. Все счетчики считаются одним SELECT с COUNT(*) FILTER (WHERE ...), без кэша и в пределах задач пользователя.
+func GetBatchStats(c *gin.Context) {
+	var requestBody struct {
+		Filters []struct {
+			Name  string `json:"name" binding:"required"`
+			Query string `json:"query"`
+		} `json:"filters" binding:"required,min=1,max=20"`
+	}
+	if err := bindJSON(c, &requestBody); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	names := make([]string, len(requestBody.Filters))
+	columns := make([]interface{}, len(requestBody.Filters))
+	seen := make(map[string]bool, len(requestBody.Filters))
+	for i, named := range requestBody.Filters {
+		if seen[named.Name] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("duplicate filter name %q", named.Name), "code": "invalid_filter"})
+			return
+		}
+		seen[named.Name] = true
+		values, err := url.ParseQuery(named.Query)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("filter %q: invalid query string", named.Name), "code": "invalid_filter"})
+			return
+		}
+		filter, err := parseTaskFilter(values)
+		if err == nil {
+			err = resolveAssignee(c, &filter)
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("filter %q: %v", named.Name, err), "code": "invalid_filter"})
+			return
+		}
+		names[i] = named.Name
+		columns[i] = filteredCount{where: filterConditions(filter), alias: "c" + strconv.Itoa(i)}
+	}
+
+	placeholders := "?"
+	for range columns[1:] {
+		placeholders += ", ?"
+	}
+	counts := make([]int64, len(columns))
+	dest := make([]interface{}, len(counts))
+	for i := range counts {
+		dest[i] = &counts[i]
+	}
+	row := scopeToUser(c, txFromContext(c).Model(&Task{})).Select(placeholders, columns...).Row()
+	if err := row.Scan(dest...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute stats"})
+		return
+	}
+
+	result := make(map[string]int64, len(names))
+	for i, name := range names {
+		result[name] = counts[i]
+	}
+	c.JSON(http.StatusOK, gin.H{"counts": result})
+}
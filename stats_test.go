@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// TestFilteredCountSQL - Каждый фильтр пакета превращается в COUNT(*) FILTER со своими параметрами
+func TestFilteredCountSQL(t *testing.T) {
+	dry := dryRunDB(t)
+	saved := db
+	db = dry
+	t.Cleanup(func() { db = saved })
+
+	filter, err := parseTaskFilter(url.Values{"priority": {"high"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns := []interface{}{
+		filteredCount{where: filterConditions(filter), alias: "c0"},
+		filteredCount{where: clause.Where{}, alias: "c1"},
+	}
+	var counts []int64
+	stmt := dry.Model(&Task{}).Select("?, ?", columns...).Find(&counts).Statement
+	sql := stmt.SQL.String()
+	for _, want := range []string{`COUNT(*) FILTER (WHERE priority = $1) AS "c0"`, `COUNT(*) FILTER (WHERE TRUE) AS "c1"`} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("SQL %s does not contain %s", sql, want)
+		}
+	}
+	if len(stmt.Vars) != 1 || stmt.Vars[0] != PriorityHigh {
+		t.Errorf("vars = %v, want [%s]", stmt.Vars, PriorityHigh)
+	}
+}
+
+// TestBatchStats - POST /tasks/stats/batch считает задачи пользователя по каждому фильтру
+// и отклоняет повторяющиеся имена и некорректные фильтры
+func TestBatchStats(t *testing.T) {
+	setupTestDB(t)
+	yesterday := time.Now().Add(-24 * time.Hour)
+	createTestTask(t, Task{Title: "Просрочена", Priority: PriorityHigh, DueDate: &yesterday, CreatedBy: stringPtr("alice")})
+	createTestTask(t, Task{Title: "Важная", Priority: PriorityHigh, CreatedBy: stringPtr("alice")})
+	createTestTask(t, Task{Title: "Обычная", CreatedBy: stringPtr("alice")})
+	createTestTask(t, Task{Title: "Чужая", Priority: PriorityHigh, CreatedBy: stringPtr("bob")})
+
+	body := `{"filters": [{"name": "overdue", "query": "filter=overdue"}, {"name": "high", "query": "priority=high"}, {"name": "all"}]}`
+	w := performRequest(http.MethodPost, "/tasks/stats/batch", body, "alice")
+	expectStatus(t, w, http.StatusOK)
+	var got struct {
+		Counts map[string]int64 `json:"counts"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int64{"overdue": 1, "high": 2, "all": 3}
+	for name, count := range want {
+		if got.Counts[name] != count {
+			t.Errorf("count %q = %d, want %d", name, got.Counts[name], count)
+		}
+	}
+
+	for _, body := range []string{
+		`{"filters": [{"name": "a", "query": "priority=high"}, {"name": "a", "query": "priority=low"}]}`,
+		`{"filters": [{"name": "bad", "query": "priority=urgent"}]}`,
+		`{"filters": []}`,
+	} {
+		w := performRequest(http.MethodPost, "/tasks/stats/batch", body, "alice")
+		expectStatus(t, w, http.StatusBadRequest)
+	}
+}
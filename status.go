@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// --- Статус задачи ---
+
+// Допустимые статусы. isCompleted хранится рядом и всегда равен status == "done".
+const (
+	StatusTodo       = "todo"
+	StatusInProgress = "in_progress"
+	StatusBlocked    = "blocked"
+	StatusDone       = "done"
+)
+
+// statusTransitions - Разрешенные переходы между статусами
+// Заблокированную задачу нельзя сразу закрыть: сначала ее нужно разблокировать.
+var statusTransitions = map[string][]string{
+	StatusTodo:       {StatusInProgress, StatusBlocked, StatusDone},
+	StatusInProgress: {StatusTodo, StatusBlocked, StatusDone},
+	StatusBlocked:    {StatusTodo, StatusInProgress},
+	StatusDone:       {StatusTodo, StatusInProgress},
+}
+
+// errInvalidTransition - Переход запрещен хотя бы для одной задачи (массовое обновление)
+var errInvalidTransition = errors.New("invalid status transition")
+
+// validStatus - Проверить, что статус входит в перечисление
+func validStatus(status string) error {
+	if _, ok := statusTransitions[status]; !ok {
+		return fmt.Errorf("invalid status %q, expected one of todo, in_progress, blocked, done", status)
+	}
+	return nil
+}
+
+// canTransition - Разрешен ли переход from -> to (оставаться в том же статусе можно всегда)
+func canTransition(from, to string) bool {
+	if from == to {
+		return true
+	}
+	for _, next := range statusTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// statusesAllowedInto - Статусы, из которых можно перейти в to (включая сам to)
+func statusesAllowedInto(to string) []string {
+	var from []string
+	for status := range statusTransitions {
+		if canTransition(status, to) {
+			from = append(from, status)
+		}
+	}
+	return from
+}
+
+// reconcileStatus - Согласовать status и isCompleted и проверить переход
+// Если клиент изменил status, isCompleted выводится из него; если изменил только
+// isCompleted, статус становится done или возвращается из done в todo.
+// storedStatus и storedCompleted заполняются в AfterFind; у новой задачи они пустые.
+func (t *Task) reconcileStatus() error {
+	t.Status = strings.ToLower(strings.TrimSpace(t.Status))
+	if t.Status == "" || (t.Status == t.storedStatus && t.IsCompleted != t.storedCompleted) {
+		switch {
+		case t.IsCompleted:
+			t.Status = StatusDone
+		case t.storedStatus != "" && t.storedStatus != StatusDone:
+			t.Status = t.storedStatus
+		default:
+			t.Status = StatusTodo
+		}
+	}
+	if err := validStatus(t.Status); err != nil {
+		return err
+	}
+	if t.storedStatus != "" && !canTransition(t.storedStatus, t.Status) {
+		return fmt.Errorf("status cannot change from %s to %s", t.storedStatus, t.Status)
+	}
+	t.IsCompleted = t.Status == StatusDone
+	// Время завершения из тела запроса игнорируется: оно ставится при переходе в done
+	// и сбрасывается, когда задачу снова открывают
+	switch {
+	case !t.IsCompleted:
+		t.CompletedAt = nil
+	case !t.storedCompleted:
+		now := time.Now()
+		t.CompletedAt = &now
+	default:
+		t.CompletedAt = t.storedCompletedAt
+	}
+	return nil
+}
+
+// ToggleTask - Переключить завершенность задачи (POST /tasks/:id/toggle)
+// Одним UPDATE без предварительного чтения: новое значение вычисляет Postgres из текущего,
+// поэтому два одновременных переключения дают два переключения, а не одно.
+// Заблокированную задачу закрыть нельзя (см. statusTransitions), для нее ответ 409.
+func ToggleTask(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	tx := txFromContext(c)
+	var task Task
+	result := tx.Model(&task).Clauses(clause.Returning{}).
+		Where("id = ? AND status <> ?", id, StatusBlocked).
+		Updates(map[string]interface{}{
+			"is_completed": gorm.Expr("NOT is_completed"),
+			"status":       gorm.Expr("CASE WHEN is_completed THEN ? ELSE ? END", StatusTodo, StatusDone),
+			"completed_at": gorm.Expr("CASE WHEN is_completed THEN NULL ELSE now() END"),
+			"updated_at":   time.Now(),
+		})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to toggle task"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		var count int64
+		tx.Model(&Task{}).Where("id = ?", id).Count(&count)
+		if count == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("status cannot change from %s to %s", StatusBlocked, StatusDone)})
+		return
+	}
+	enrichTask(c, &task)
+	c.JSON(http.StatusOK, task)
+}
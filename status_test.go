@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// TestToggleTaskConcurrent - N одновременных переключений дают ровно N переключений:
+// итог зависит только от четности N, а ответы чередуют завершенность
+func TestToggleTaskConcurrent(t *testing.T) {
+	setupTestDB(t)
+	for _, toggles := range []int{10, 7} {
+		task := createTestTask(t, Task{Title: fmt.Sprintf("Toggle %d times", toggles)})
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		completedResponses := 0
+		for i := 0; i < toggles; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				w := performRequest(http.MethodPost, fmt.Sprintf("/tasks/%d/toggle", task.ID), "", "")
+				var toggled Task
+				if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &toggled) != nil {
+					t.Errorf("toggle: status %d, body %s", w.Code, w.Body.String())
+					return
+				}
+				if toggled.IsCompleted {
+					mu.Lock()
+					completedResponses++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		var final Task
+		if err := db.First(&final, task.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		wantCompleted := toggles%2 == 1
+		wantStatus := StatusTodo
+		if wantCompleted {
+			wantStatus = StatusDone
+		}
+		if final.IsCompleted != wantCompleted || final.Status != wantStatus || (final.CompletedAt != nil) != wantCompleted {
+			t.Errorf("after %d toggles: isCompleted %v, status %q, completedAt %v; want %v, %q",
+				toggles, final.IsCompleted, final.Status, final.CompletedAt, wantCompleted, wantStatus)
+		}
+		if want := (toggles + 1) / 2; completedResponses != want {
+			t.Errorf("after %d toggles: %d responses completed the task, want %d", toggles, completedResponses, want)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// --- Подзадачи ---
+
+// Автоматически завершать родительскую задачу, когда завершены все подзадачи (AUTO_COMPLETE_PARENT)
+var autoCompleteParent = false
+
+// validateParent - Проверить, что указанная родительская задача существует и не совпадает с самой задачей
+func validateParent(tx *gorm.DB, task *Task) error {
+	if task.ParentID == nil {
+		return nil
+	}
+	if task.ID != 0 && *task.ParentID == task.ID {
+		return errors.New("task cannot be its own parent")
+	}
+	var count int64
+	tx.Model(&Task{}).Where("id = ?", *task.ParentID).Count(&count)
+	if count == 0 {
+		return errors.New("parent task not found")
+	}
+	if task.ID != 0 && isAncestorOrSelf(tx, task.ID, *task.ParentID) {
+		return errors.New("a task cannot be moved under its own subtask")
+	}
+	return nil
+}
+
+// isAncestorOrSelf - Является ли задача ancestorID предком задачи taskID (или ею самой)
+// UNION (а не UNION ALL) защищает от зацикливания на уже испорченных данных.
+func isAncestorOrSelf(tx *gorm.DB, ancestorID, taskID uint) bool {
+	var found bool
+	tx.Raw(`WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id FROM tasks WHERE id = ?
+			UNION
+			SELECT t.id, t.parent_id FROM tasks t JOIN ancestors a ON t.id = a.parent_id
+		)
+		SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = ?)`, taskID, ancestorID).Scan(&found)
+	return found
+}
+
+// siblings - Условие отбора задач с тем же родителем (NULL - задачи верхнего уровня)
+func siblings(tx *gorm.DB, parentID *uint) *gorm.DB {
+	if parentID == nil {
+		return tx.Model(&Task{}).Where("parent_id IS NULL")
+	}
+	return tx.Model(&Task{}).Where("parent_id = ?", *parentID)
+}
+
+// nextPosition - Позиция для новой задачи в конце списка родителя
+func nextPosition(tx *gorm.DB, parentID *uint) int {
+	var maxPosition *int
+	siblings(tx, parentID).Select("MAX(position)").Scan(&maxPosition)
+	if maxPosition == nil {
+		return 0
+	}
+	return *maxPosition + 1
+}
+
+// placeTask - Поставить задачу на место index среди подзадач ее (нового) родителя
+// Сначала закрывается дыра на старом месте, затем освобождается новое, поэтому
+// перемещение вверх и вниз по списку работает одинаково и позиции не совпадают.
+// Индекс за концом списка означает "в конец". task.ParentID - уже новый родитель.
+func placeTask(tx *gorm.DB, task *Task, oldParentID *uint, oldPosition, index int) error {
+	if err := siblings(tx, oldParentID).
+		Where("id <> ? AND position > ?", task.ID, oldPosition).
+		UpdateColumn("position", gorm.Expr("position - 1")).Error; err != nil {
+		return err
+	}
+	var count int64
+	if err := siblings(tx, task.ParentID).Where("id <> ?", task.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if index > int(count) {
+		index = int(count)
+	}
+	if err := siblings(tx, task.ParentID).
+		Where("id <> ? AND position >= ?", task.ID, index).
+		UpdateColumn("position", gorm.Expr("position + 1")).Error; err != nil {
+		return err
+	}
+	task.Position = index
+	return nil
+}
+
+// errMovedConcurrently - Задачу перенесли к другому родителю, пока перенос ждал блокировку
+var errMovedConcurrently = errors.New("task was moved concurrently")
+
+// parentKey - Ключ списка подзадач для порядка блокировок (0 - верхний уровень)
+func parentKey(parentID *uint) uint {
+	if parentID == nil {
+		return 0
+	}
+	return *parentID
+}
+
+// lockForMove - Загрузить задачу id для переноса к newParentID под блокировкой
+// Сначала блокируются строки списков старого и нового родителя (по ключу родителя, внутри - по id),
+// затем задача перечитывается: одновременные перестановки в одном списке идут по очереди и
+// считают позиции по актуальным данным, а встречные переносы не блокируют друг друга.
+// Возвращает id заблокированных задач: только у них перенос может сдвинуть позиции.
+func lockForMove(tx *gorm.DB, id uint, newParentID func(current *uint) *uint, task *Task) ([]uint, error) {
+	var current Task
+	if err := tx.First(&current, id).Error; err != nil {
+		return nil, err
+	}
+	lists := []*uint{current.ParentID}
+	if target := newParentID(current.ParentID); parentKey(target) != parentKey(current.ParentID) {
+		lists = append(lists, target)
+		if parentKey(target) < parentKey(current.ParentID) {
+			lists[0], lists[1] = lists[1], lists[0]
+		}
+	}
+	var locked []uint
+	for _, parentID := range lists {
+		var ids []uint
+		if err := siblings(tx, parentID).Order("id").Clauses(clause.Locking{Strength: "UPDATE"}).Pluck("id", &ids).Error; err != nil {
+			return nil, err
+		}
+		locked = append(locked, ids...)
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(task, id).Error; err != nil {
+		return nil, err
+	}
+	if parentKey(task.ParentID) != parentKey(current.ParentID) {
+		return nil, errMovedConcurrently
+	}
+	return locked, nil
+}
+
+// respondMoveError - Ответ на ошибку переноса задачи
+func respondMoveError(c *gin.Context, err error) {
+	var fieldErr *fieldError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	case errors.As(err, &fieldErr):
+		respondValidationError(c, err)
+	case errors.Is(err, errMovedConcurrently):
+		c.JSON(http.StatusConflict, gin.H{"error": "Task was moved by another request; retry", "code": "concurrent_move"})
+	case isDuplicateTitle(err):
+		respondDuplicateTitle(c)
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move task"})
+	}
+}
+
+// MoveTaskTo - Перетащить задачу на указанное место (POST /tasks/:id/move-to)
+// Тело: {"index": 0, "parentId": 5, "status": "in_progress"}; parentId и status необязательны,
+// parentId: null переносит задачу на верхний уровень. Задача читается и соседи пересчитываются
+// в одной транзакции под блокировкой списков (см. lockForMove).
+func MoveTaskTo(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var requestBody struct {
+		Index    *int            `json:"index"`
+		ParentID json.RawMessage `json:"parentId"`
+		Status   *string         `json:"status"`
+	}
+	if err := bindJSON(c, &requestBody); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if requestBody.Index == nil || *requestBody.Index < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "index must be a non-negative integer"})
+		return
+	}
+	newParentID := func(current *uint) *uint { return current }
+	if len(requestBody.ParentID) > 0 {
+		var parentID *uint
+		if err := json.Unmarshal(requestBody.ParentID, &parentID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parentId value"})
+			return
+		}
+		newParentID = func(*uint) *uint { return parentID }
+	}
+
+	var task Task
+	var affected []uint
+	err := txFromContext(c).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if affected, err = lockForMove(tx, id, newParentID, &task); err != nil {
+			return err
+		}
+		oldParentID, oldPosition := task.ParentID, task.Position
+		task.ParentID = newParentID(oldParentID)
+		if requestBody.Status != nil {
+			task.Status = *requestBody.Status
+		}
+		if err := validateTask(tx, &task); err != nil {
+			return err
+		}
+		if err := placeTask(tx, &task, oldParentID, oldPosition, *requestBody.Index); err != nil {
+			return err
+		}
+		return tx.Model(&task).
+			Select("parent_id", "position", "status", "is_completed", "completed_at", "updated_at", "last_activity_at").
+			Updates(&task).Error
+	})
+	if err != nil {
+		respondMoveError(c, err)
+		return
+	}
+	invalidateTaskCachesOnCommit(c.Request.Context(), affected...) // Соседи сдвинуты UpdateColumn без хуков
+	enrichTask(c, &task)
+	c.JSON(http.StatusOK, task)
+}
+
+// MoveTask - Перенести задачу к другому родителю
+// Тело: {"newParentId": 5} или {"newParentId": null} для переноса на верхний уровень.
+// Задача встает в конец списка нового родителя, позиции у старого родителя сдвигаются.
+func MoveTask(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	var requestBody struct {
+		NewParentID *uint `json:"newParentId"`
+	}
+	if err := bindJSON(c, &requestBody); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	var task Task
+	var affected []uint
+	err := txFromContext(c).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if affected, err = lockForMove(tx, id, func(*uint) *uint { return requestBody.NewParentID }, &task); err != nil {
+			return err
+		}
+		oldParentID, oldPosition := task.ParentID, task.Position
+		task.ParentID = requestBody.NewParentID
+		if err := validateTask(tx, &task); err != nil {
+			return err
+		}
+		// Закрываем дыру в списке старого родителя
+		if err := siblings(tx, oldParentID).
+			Where("id <> ? AND position > ?", task.ID, oldPosition).
+			UpdateColumn("position", gorm.Expr("position - 1")).Error; err != nil {
+			return err
+		}
+		task.Position = nextPosition(tx, task.ParentID)
+		return tx.Model(&task).Select("parent_id", "position", "updated_at").Updates(&task).Error
+	})
+	if err != nil {
+		respondMoveError(c, err)
+		return
+	}
+	// Хуки сбросили кэш только самой задачи; соседи со старыми позициями сбрасываются
+	// после фиксации, чтобы параллельное чтение не вернуло их в кэш
+	invalidateTaskCachesOnCommit(c.Request.Context(), affected...)
+	enrichTask(c, &task)
+	c.JSON(http.StatusOK, task)
+}
+
+// attachProgress - Вычислить прогресс для задач, у которых есть подзадачи
+// Один GROUP BY запрос на весь список, чтобы избежать N+1.
+func attachProgress(tx *gorm.DB, tasks []Task) {
+	if len(tasks) == 0 {
+		return
+	}
+	ids := make([]uint, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	var rows []struct {
+		ParentID  uint
+		Total     int64
+		Completed int64
+	}
+	err := tx.Model(&Task{}).
+		Select("parent_id, COUNT(*) AS total, COUNT(*) FILTER (WHERE is_completed) AS completed").
+		Where("parent_id IN ?", ids).
+		Group("parent_id").
+		Scan(&rows).Error
+	if err != nil {
+		log.Printf("Failed to compute subtask progress: %v", err)
+		return
+	}
+
+	progress := make(map[uint]float64, len(rows))
+	for _, row := range rows {
+		progress[row.ParentID] = float64(row.Completed) / float64(row.Total)
+	}
+	for i := range tasks {
+		if value, ok := progress[tasks[i].ID]; ok {
+			tasks[i].Progress = &value
+		}
+	}
+}
+
+// completeParentIfDone - Завершить родительскую задачу, если у нее не осталось незавершенных подзадач
+// Сохранение родителя снова вызывает AfterSave, поэтому завершение поднимается вверх по дереву.
+// Родитель, из статуса которого переход в done запрещен (blocked), остается как есть.
+func completeParentIfDone(tx *gorm.DB, parentID uint) error {
+	var parent Task
+	if err := tx.First(&parent, parentID).Error; err != nil || parent.IsCompleted {
+		return nil
+	}
+	if !canTransition(parent.Status, StatusDone) {
+		return nil
+	}
+	var pending int64
+	if err := tx.Model(&Task{}).Where("parent_id = ? AND is_completed = ?", parentID, false).Count(&pending).Error; err != nil {
+		return err
+	}
+	if pending > 0 {
+		return nil
+	}
+	now := time.Now()
+	parent.Status = StatusDone
+	parent.IsCompleted = true
+	parent.CompletedAt = &now
+	return tx.Save(&parent).Error
+}
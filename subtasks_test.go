@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// TestMoveTaskToConcurrent - Одновременные перетаскивания в одном списке не дают одинаковых позиций
+func TestMoveTaskToConcurrent(t *testing.T) {
+	setupTestDB(t)
+	parent := createTestTask(t, Task{Title: "Parent"})
+	const children = 5
+	ids := make([]uint, children)
+	for i := range ids {
+		ids[i] = createTestTask(t, Task{Title: fmt.Sprintf("Child %d", i), ParentID: &parent.ID, Position: i}).ID
+	}
+
+	var wg sync.WaitGroup
+	statuses := make(chan int, 4*children)
+	for i := 0; i < 4*children; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"index": %d}`, (i*3)%children)
+			statuses <- performRequest(http.MethodPost, fmt.Sprintf("/tasks/%d/move-to", ids[i%children]), body, "").Code
+		}(i)
+	}
+	wg.Wait()
+	close(statuses)
+	for status := range statuses {
+		if status != http.StatusOK {
+			t.Errorf("move-to status = %d, want 200", status)
+		}
+	}
+
+	var positions []int
+	if err := db.Model(&Task{}).Where("parent_id = ?", parent.ID).Order("position").Pluck("position", &positions).Error; err != nil {
+		t.Fatal(err)
+	}
+	for i, position := range positions {
+		if position != i {
+			t.Fatalf("positions after concurrent moves = %v, want 0..%d without repeats", positions, children-1)
+		}
+	}
+}
+
+// TestAutoCompleteParentRespectsTransitions - AUTO_COMPLETE_PARENT завершает родителя после последней
+// подзадачи, но заблокированный родитель остается blocked: переход blocked -> done запрещен
+func TestAutoCompleteParentRespectsTransitions(t *testing.T) {
+	setupTestDB(t)
+	saved := autoCompleteParent
+	autoCompleteParent = true
+	t.Cleanup(func() { autoCompleteParent = saved })
+
+	for _, tt := range []struct{ status, want string }{
+		{StatusInProgress, StatusDone},
+		{StatusBlocked, StatusBlocked},
+	} {
+		parent := createTestTask(t, Task{Title: "Родитель " + tt.status, Status: tt.status})
+		child := createTestTask(t, Task{Title: "Подзадача", ParentID: &parent.ID})
+
+		w := performRequest(http.MethodPatch, fmt.Sprintf("/tasks/%d", child.ID), `{"status": "done"}`, "")
+		expectStatus(t, w, http.StatusOK)
+
+		var got Task
+		if err := db.First(&got, parent.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		if got.Status != tt.want || got.IsCompleted != (tt.want == StatusDone) {
+			t.Errorf("%s parent after its last subtask is done: status %s, isCompleted %v; want %s", tt.status, got.Status, got.IsCompleted, tt.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// --- Синхронизация с внешними системами ---
+
+// upsertColumns - Колонки, которые перезаписываются при повторной синхронизации задачи
+// deleted_at сбрасывается, чтобы повторная синхронизация восстанавливала удаленную задачу.
+var upsertColumns = []string{
+	"title", "description", "priority", "due_date", "tags", "status",
+	"is_completed", "parent_id", "assignee_id", "updated_at", "last_activity_at", "deleted_at",
+}
+
+// UpsertTaskByExternalID - Создать или обновить задачу по ID из внешней системы
+// Повторный вызов с теми же данными ничего не меняет, поэтому синхронизация идемпотентна.
+func UpsertTaskByExternalID(c *gin.Context) {
+	externalID := strings.TrimSpace(c.Param("externalId"))
+	if externalID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "externalId is required"})
+		return
+	}
+
+	var task Task
+	if err := bindTaskJSON(c, &task); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	task.ID = 0
+	task.ExternalID = &externalID
+	task.CreatedBy = currentUserID(c) // Записывается только при создании: created_by нет в upsertColumns
+	tx := txFromContext(c)
+	if err := validateTask(tx, &task); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	var existing int64
+	tx.Unscoped().Model(&Task{}).Where("external_id = ?", externalID).Count(&existing)
+	created := existing == 0
+	if created && !checkTaskLimit(c, 1) {
+		return
+	}
+	if created {
+		task.Position = nextPosition(tx, task.ParentID)
+	}
+
+	if err := upsertByExternalID(tx, &task); err != nil {
+		if isDuplicateTitle(err) {
+			respondDuplicateTitle(c)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upsert task"})
+		return
+	}
+	enrichTask(c, &task)
+
+	status, outcome := http.StatusOK, "updated"
+	if created {
+		status, outcome = http.StatusCreated, "created"
+		c.Header("Location", resourceLocation("tasks", task.ID))
+	}
+	c.JSON(status, gin.H{"result": outcome, "task": task})
+}
+
+// upsertByExternalID - Вставить задачу или обновить строку с тем же external_id одним запросом
+// После записи задача перечитывается: при обновлении created_at и position остаются прежними.
+// Вебхук получает task.created или task.updated в зависимости от того, что сделал запрос.
+func upsertByExternalID(tx *gorm.DB, task *Task) error {
+	task.upserting = true
+	defer func() { task.upserting = false }()
+	result := tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "external_id"}},
+		DoUpdates: append(clause.AssignmentColumns(upsertColumns), clause.Assignment{
+			// Задача, которая остается завершенной, сохраняет исходное время завершения
+			Column: clause.Column{Name: "completed_at"},
+			Value:  gorm.Expr("CASE WHEN tasks.is_completed AND excluded.is_completed THEN tasks.completed_at ELSE excluded.completed_at END"),
+		}),
+	}).Create(task)
+	if result.Error != nil {
+		return result.Error
+	}
+	// Строку, обновленную через ON CONFLICT DO UPDATE, транзакция держит заблокированной (xmax <> 0),
+	// у вставленной xmax = 0
+	var inserted bool
+	if err := tx.Raw("SELECT xmax = 0 FROM tasks WHERE id = ?", task.ID).Scan(&inserted).Error; err != nil {
+		return err
+	}
+	if err := tx.First(task, task.ID).Error; err != nil {
+		return err
+	}
+	event := webhookTaskUpdated
+	if inserted {
+		event = webhookTaskCreated
+	}
+	return enqueueWebhookEvent(tx.Session(&gorm.Session{NewDB: true}), event, task)
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+// TestUpsertWebhookEvents - Повторная синхронизация по externalId дает task.updated, а не task.created
+func TestUpsertWebhookEvents(t *testing.T) {
+	setupTestDB(t)
+	cleanWebhooks(t)
+	if err := db.Create(&Webhook{URL: "http://127.0.0.1:9/hook"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for _, body := range []string{`{"title": "Из трекера"}`, `{"title": "Из трекера", "priority": "high"}`} {
+		w := performRequest(http.MethodPut, "/tasks/external/JIRA-1", body, "")
+		if w.Code != http.StatusCreated && w.Code != http.StatusOK {
+			t.Fatalf("upsert status = %d; body: %s", w.Code, w.Body.String())
+		}
+	}
+
+	var events []string
+	if err := db.Model(&WebhookDelivery{}).Order("id").Pluck("event", &events).Error; err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{webhookTaskCreated, webhookTaskUpdated}; !slices.Equal(events, want) {
+		t.Errorf("webhook events = %v, want %v", events, want)
+	}
+}
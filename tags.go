@@ -0,0 +1,132 @@
+package main
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- Теги ---
+
+// Цвет тега по умолчанию (нейтральный серый)
+const defaultTagColor = "#9e9e9e"
+
+// hexColorPattern - Цвет в формате #rgb или #rrggbb
+var hexColorPattern = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Tag - Оформление тега (цвет для отображения в интерфейсе)
+// Сами теги по-прежнему хранятся в задаче строкой через запятую.
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"uniqueIndex"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TagCount - Тег, количество задач, в которых он используется, и его цвет
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+	Color string `json:"color"`
+}
+
+// splitTags - Разбить строку тегов через запятую на отдельные теги
+func splitTags(raw string) []string {
+	var tags []string
+	for _, part := range strings.Split(raw, ",") {
+		if tag := strings.TrimSpace(part); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// GetTags - Получить список уникальных тегов с количеством задач
+// Теги пока хранятся строкой через запятую, поэтому агрегируем их в памяти.
+func GetTags(c *gin.Context) {
+	prefix := strings.ToLower(strings.TrimSpace(c.Query("prefix")))
+
+	tx := txFromContext(c)
+	var rawTags []string
+	if result := tx.Model(&Task{}).Where("tags <> ''").Pluck("tags", &rawTags); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load tags"})
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, raw := range rawTags {
+		// Один и тот же тег в задаче считаем один раз
+		seen := make(map[string]bool)
+		for _, tag := range splitTags(raw) {
+			if seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			if prefix != "" && !strings.HasPrefix(strings.ToLower(tag), prefix) {
+				continue
+			}
+			counts[tag]++
+		}
+	}
+
+	colors := make(map[string]string)
+	var styles []Tag
+	tx.Find(&styles)
+	for _, style := range styles {
+		colors[style.Name] = style.Color
+	}
+
+	result := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		color := colors[tag]
+		if color == "" {
+			color = defaultTagColor
+		}
+		result = append(result, TagCount{Tag: tag, Count: count, Color: color})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Tag < result[j].Tag
+	})
+
+	c.JSON(http.StatusOK, result)
+}
+
+// SetTagColor - Задать цвет тега
+func SetTagColor(c *gin.Context) {
+	name := strings.TrimSpace(c.Param("name"))
+	var requestBody struct {
+		Color string `json:"color" binding:"required"`
+	}
+	if err := bindJSON(c, &requestBody); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Color is required"})
+		return
+	}
+	if !hexColorPattern.MatchString(requestBody.Color) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Color must be a hex value like #ff8800"})
+		return
+	}
+
+	tx := txFromContext(c)
+	tag := Tag{Name: name}
+	tx.Where(Tag{Name: name}).FirstOrInit(&tag)
+	tag.Color = strings.ToLower(requestBody.Color)
+	if result := tx.Save(&tag); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save tag"})
+		return
+	}
+	c.JSON(http.StatusOK, tag)
+}
+
+// DeleteTagColor - Сбросить цвет тега на цвет по умолчанию
+func DeleteTagColor(c *gin.Context) {
+	txFromContext(c).Where("name = ?", strings.TrimSpace(c.Param("name"))).Delete(&Tag{})
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// --- Кэш задач для GET /tasks/:id ---
+
+// taskLRU - LRU-кэш строк задач (без вычисляемых полей) с TTL
+// Вычисляемые поля (прогресс, время, чек-лист) считаются при каждом запросе,
+// поэтому в кэше только сама строка таблицы tasks.
+type taskLRU struct {
+	mu         sync.Mutex
+	size       int // Максимум записей (TASK_CACHE_SIZE), 0 - кэш выключен
+	ttl        time.Duration
+	items      map[uint]*list.Element
+	order      *list.List // Начало списка - недавно использованные
+	generation uint64     // Растет при каждой инвалидации
+}
+
+type taskCacheEntry struct {
+	task    Task
+	expires time.Time
+}
+
+var taskCache = newTaskLRU(0, time.Minute)
+
+// newTaskLRU - Кэш на size записей с временем жизни ttl
+func newTaskLRU(size int, ttl time.Duration) *taskLRU {
+	return &taskLRU{size: size, ttl: ttl, items: make(map[uint]*list.Element), order: list.New()}
+}
+
+// enabled - Включен ли кэш
+func (l *taskLRU) enabled() bool {
+	return l.size > 0
+}
+
+// get - Задача из кэша и текущее поколение (его нужно передать в put после чтения из БД)
+func (l *taskLRU) get(id uint) (Task, uint64, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	el, ok := l.items[id]
+	if !ok {
+		return Task{}, l.generation, false
+	}
+	entry := el.Value.(*taskCacheEntry)
+	if time.Now().After(entry.expires) {
+		l.order.Remove(el)
+		delete(l.items, id)
+		return Task{}, l.generation, false
+	}
+	l.order.MoveToFront(el)
+	return entry.task, l.generation, true
+}
+
+// put - Сохранить задачу, прочитанную из БД
+// Если с момента get была инвалидация, строка могла устареть - тогда она не кэшируется.
+func (l *taskLRU) put(task Task, generation uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.enabled() || generation != l.generation {
+		return
+	}
+	entry := &taskCacheEntry{task: task, expires: time.Now().Add(l.ttl)}
+	if el, ok := l.items[task.ID]; ok {
+		el.Value = entry
+		l.order.MoveToFront(el)
+		return
+	}
+	l.items[task.ID] = l.order.PushFront(entry)
+	if l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.items, oldest.Value.(*taskCacheEntry).task.ID)
+	}
+}
+
+// remove - Убрать задачу из кэша; id 0 - очистить кэш целиком
+// (изменение по условию, когда затронутые id неизвестны).
+func (l *taskLRU) remove(id uint) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.generation++
+	if id == 0 {
+		l.items = make(map[uint]*list.Element)
+		l.order.Init()
+		return
+	}
+	if el, ok := l.items[id]; ok {
+		l.order.Remove(el)
+		delete(l.items, id)
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// --- Учет времени по задачам ---
+
+// TimeEntry - Запись о затраченном на задачу времени
+// Запись с пустым EndedAt - запущенный таймер.
+type TimeEntry struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	TaskID          uint       `json:"taskId" gorm:"index"`
+	StartedAt       time.Time  `json:"startedAt"`
+	EndedAt         *time.Time `json:"endedAt"`
+	DurationSeconds int64      `json:"durationSeconds"`
+	Note            string     `json:"note"`
+	CreatedAt       time.Time  `json:"createdAt"`
+}
+
+// attachTimeSpent - Посчитать суммарное время (в секундах) по завершенным записям для списка задач
+func attachTimeSpent(tx *gorm.DB, tasks []Task) {
+	if len(tasks) == 0 {
+		return
+	}
+	ids := make([]uint, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+
+	var rows []struct {
+		TaskID uint
+		Total  int64
+	}
+	err := tx.Model(&TimeEntry{}).
+		Select("task_id, SUM(duration_seconds) AS total").
+		Where("task_id IN ? AND ended_at IS NOT NULL", ids).
+		Group("task_id").
+		Scan(&rows).Error
+	if err != nil {
+		log.Printf("Failed to compute time spent: %v", err)
+		return
+	}
+
+	totals := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		totals[row.TaskID] = row.Total
+	}
+	for i := range tasks {
+		tasks[i].TotalTimeSpent = totals[tasks[i].ID]
+	}
+}
+
+// AddTimeEntry - Запустить/остановить таймер или записать длительность вручную
+// Тело: {"action":"start"}, {"action":"stop"} или {"durationSeconds":1800,"note":"..."}.
+func AddTimeEntry(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	tx := txFromContext(c)
+	var task Task
+	if result := tx.First(&task, id); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	var requestBody struct {
+		Action          string `json:"action"`
+		DurationSeconds int64  `json:"durationSeconds"`
+		Note            string `json:"note"`
+	}
+	if err := bindJSON(c, &requestBody); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	var running TimeEntry
+	hasRunning := tx.Where("task_id = ? AND ended_at IS NULL", task.ID).Limit(1).Find(&running).RowsAffected > 0
+	now := time.Now()
+
+	switch requestBody.Action {
+	case "start":
+		if hasRunning {
+			c.JSON(http.StatusConflict, gin.H{"error": "Timer is already running for this task"})
+			return
+		}
+		entry := TimeEntry{TaskID: task.ID, StartedAt: now, Note: requestBody.Note}
+		tx.Create(&entry)
+		c.JSON(http.StatusCreated, entry)
+	case "stop":
+		if !hasRunning {
+			c.JSON(http.StatusConflict, gin.H{"error": "No running timer for this task"})
+			return
+		}
+		running.EndedAt = &now
+		running.DurationSeconds = int64(now.Sub(running.StartedAt).Seconds())
+		if requestBody.Note != "" {
+			running.Note = requestBody.Note
+		}
+		tx.Save(&running)
+		c.JSON(http.StatusOK, running)
+	case "":
+		if requestBody.DurationSeconds <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Either action (start/stop) or a positive durationSeconds is required"})
+			return
+		}
+		started := now.Add(-time.Duration(requestBody.DurationSeconds) * time.Second)
+		entry := TimeEntry{
+			TaskID:          task.ID,
+			StartedAt:       started,
+			EndedAt:         &now,
+			DurationSeconds: requestBody.DurationSeconds,
+			Note:            requestBody.Note,
+		}
+		tx.Create(&entry)
+		c.JSON(http.StatusCreated, entry)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "action must be start or stop"})
+	}
+}
+
+// GetTimeEntries - Получить записи времени по задаче
+func GetTimeEntries(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	tx := txFromContext(c)
+	var task Task
+	if result := tx.First(&task, id); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+		return
+	}
+
+	var entries []TimeEntry
+	tx.Where("task_id = ?", task.ID).Order("started_at, id").Find(&entries)
+	c.JSON(http.StatusOK, entries)
+}
+
+// TimeStat - Суммарное время по группе (тегу или приоритету)
+type TimeStat struct {
+	Key          string `json:"key"`
+	TotalSeconds int64  `json:"totalSeconds"`
+}
+
+// GetTimeStats - Суммарное затраченное время с группировкой ?groupBy=priority|tag
+func GetTimeStats(c *gin.Context) {
+	groupBy := c.DefaultQuery("groupBy", "priority")
+	if groupBy != "priority" && groupBy != "tag" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "groupBy must be priority or tag"})
+		return
+	}
+
+	column := "tasks.priority"
+	if groupBy == "tag" {
+		column = "tasks.tags"
+	}
+	var rows []TimeStat
+	err := txFromContext(c).Table("time_entries").
+		Select(column + " AS key, SUM(time_entries.duration_seconds) AS total_seconds").
+		Joins("JOIN tasks ON tasks.id = time_entries.task_id AND tasks.deleted_at IS NULL").
+		Where("time_entries.ended_at IS NOT NULL").
+		Group(column).
+		Scan(&rows).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute time stats"})
+		return
+	}
+
+	// Теги хранятся строкой через запятую, поэтому раскладываем суммы по отдельным тегам
+	if groupBy == "tag" {
+		totals := make(map[string]int64)
+		for _, row := range rows {
+			for _, tag := range splitTags(row.Key) {
+				totals[tag] += row.TotalSeconds
+			}
+		}
+		rows = rows[:0]
+		for tag, total := range totals {
+			rows = append(rows, TimeStat{Key: tag, TotalSeconds: total})
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].TotalSeconds != rows[j].TotalSeconds {
+			return rows[i].TotalSeconds > rows[j].TotalSeconds
+		}
+		return rows[i].Key < rows[j].Key
+	})
+
+	c.JSON(http.StatusOK, gin.H{"groupBy": groupBy, "stats": rows})
+}
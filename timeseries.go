@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// --- Статистика во времени (для графиков burndown/velocity) ---
+
+// Не больше стольких интервалов в одном ответе: год по дням с запасом
+const maxTimeseriesBuckets = 400
+
+// timeseriesIntervals - Допустимые ?interval= и период по умолчанию (сколько интервалов назад от ?to=)
+var timeseriesIntervals = map[string]int{"day": 30, "week": 12, "month": 12}
+
+// TimeseriesPoint - Созданные и завершенные задачи за один интервал
+type TimeseriesPoint struct {
+	Bucket    string `json:"bucket"` // Начало интервала, YYYY-MM-DD в часовом поясе запроса
+	Created   int64  `json:"created"`
+	Completed int64  `json:"completed"`
+}
+
+// bucketStart - Начало интервала, в который попадает t (неделя начинается с WEEK_START)
+func bucketStart(t time.Time, interval string, loc *time.Location) time.Time {
+	switch interval {
+	case "week":
+		start, _ := weekBounds(t, loc)
+		return start
+	case "month":
+		t = t.In(loc)
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	}
+	return startOfDay(t, loc)
+}
+
+// nextBucket - Начало следующего интервала
+func nextBucket(start time.Time, interval string) time.Time {
+	switch interval {
+	case "week":
+		return start.AddDate(0, 0, 7)
+	case "month":
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// postgresTimezone - Имя пояса для AT TIME ZONE
+// time.Local не знает своего IANA-имени, поэтому для пояса сервера берется TZ (по умолчанию UTC).
+func postgresTimezone(loc *time.Location) string {
+	if loc != time.Local {
+		return loc.String()
+	}
+	if name := os.Getenv("TZ"); name != "" {
+		if _, err := time.LoadLocation(name); err == nil {
+			return name
+		}
+	}
+	return "UTC"
+}
+
+// countByBucket - Число задач по интервалам: date_trunc по колонке column в поясе tz
+// date_trunc('week') начинает неделю с понедельника, поэтому для другого WEEK_START
+// время сдвигается на shift дней до усечения и обратно после.
+func countByBucket(query *gorm.DB, column, interval, tz string) (map[string]int64, error) {
+	shift := 0
+	if interval == "week" {
+		shift = (int(time.Monday) - int(weekStart) + 7) % 7
+	}
+	bucket := fmt.Sprintf(
+		"to_char(date_trunc(?, (%s AT TIME ZONE ?) + make_interval(days => ?)) - make_interval(days => ?), 'YYYY-MM-DD')",
+		column)
+	var rows []struct {
+		Bucket string
+		Count  int64
+	}
+	err := query.
+		Select(bucket+" AS bucket, COUNT(*) AS count", interval, tz, shift, shift).
+		Group("bucket").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Bucket] = row.Count
+	}
+	return counts, nil
+}
+
+// GetStatsTimeseries - Созданные и завершенные задачи по интервалам (GET /tasks/stats/timeseries)
+// ?interval=day|week|month, ?from= и ?to= (RFC3339 или смещение вроде -30d; по умолчанию
+// последние 30 дней, 12 недель или 12 месяцев), часовой пояс - как у календаря (?tz=).
+// Учитываются те же фильтры, что и в GET /tasks, и только задачи текущего пользователя.
+func GetStatsTimeseries(c *gin.Context) {
+	interval := c.DefaultQuery("interval", "day")
+	defaultBuckets, ok := timeseriesIntervals[interval]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "interval must be day, week or month"})
+		return
+	}
+	loc, err := requestLocation(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	to := time.Now()
+	if raw := c.Query("to"); raw != "" {
+		if to, err = parseFilterTime(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid to value %q", raw)})
+			return
+		}
+	}
+	from := bucketStart(to, interval, loc)
+	for i := 1; i < defaultBuckets; i++ {
+		from = bucketStart(from.Add(-time.Nanosecond), interval, loc)
+	}
+	if raw := c.Query("from"); raw != "" {
+		if from, err = parseFilterTime(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid from value %q", raw)})
+			return
+		}
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+
+	var buckets []time.Time
+	for start := bucketStart(from, interval, loc); start.Before(to); start = nextBucket(start, interval) {
+		if len(buckets) == maxTimeseriesBuckets {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("range spans more than %d intervals; use a larger interval", maxTimeseriesBuckets)})
+			return
+		}
+		buckets = append(buckets, start)
+	}
+
+	filter, err := parseTaskFilter(c.Request.URL.Query())
+	if err == nil {
+		err = resolveAssignee(c, &filter)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tasks := func() *gorm.DB {
+		return applyTaskFilter(scopeToUser(c, txFromContext(c).Model(&Task{})), filter)
+	}
+
+	tz := postgresTimezone(loc)
+	created, err := countByBucket(tasks().Where("created_at >= ? AND created_at < ?", from, to), "created_at", interval, tz)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute timeseries"})
+		return
+	}
+	completed, err := countByBucket(
+		tasks().Where("is_completed AND completed_at >= ? AND completed_at < ?", from, to), "completed_at", interval, tz)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute timeseries"})
+		return
+	}
+
+	series := make([]TimeseriesPoint, len(buckets))
+	for i, start := range buckets {
+		key := start.Format(time.DateOnly)
+		series[i] = TimeseriesPoint{Bucket: key, Created: created[key], Completed: completed[key]}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"interval": interval,
+		"from":     from,
+		"to":       to,
+		"timezone": loc.String(),
+		"series":   series,
+	})
+}
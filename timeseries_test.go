@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBucketStart(t *testing.T) {
+	saved := weekStart
+	t.Cleanup(func() { weekStart = saved })
+	at := time.Date(2026, 3, 4, 15, 30, 0, 0, time.UTC) // Среда
+
+	tests := []struct {
+		interval string
+		week     time.Weekday
+		want     string
+	}{
+		{interval: "day", week: time.Monday, want: "2026-03-04"},
+		{interval: "week", week: time.Monday, want: "2026-03-02"},
+		{interval: "week", week: time.Sunday, want: "2026-03-01"},
+		{interval: "month", week: time.Monday, want: "2026-03-01"},
+	}
+	for _, tt := range tests {
+		weekStart = tt.week
+		if got := bucketStart(at, tt.interval, time.UTC).Format(time.DateOnly); got != tt.want {
+			t.Errorf("bucketStart(%s, week from %s) = %s, want %s", tt.interval, tt.week, got, tt.want)
+		}
+	}
+}
+
+// TestStatsTimeseries - Созданные и завершенные задачи считаются по дням created_at и completed_at,
+// пустые дни присутствуют в ряду с нулями
+func TestStatsTimeseries(t *testing.T) {
+	setupTestDB(t)
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 10, 0, 0, 0, time.UTC) }
+	completed := day(3)
+	createTestTask(t, Task{Title: "Создана 1", CreatedAt: day(1)})
+	createTestTask(t, Task{Title: "Создана 1, завершена 3", CreatedAt: day(1), Status: StatusDone, IsCompleted: true, CompletedAt: &completed})
+	createTestTask(t, Task{Title: "До периода", CreatedAt: time.Date(2026, 2, 20, 10, 0, 0, 0, time.UTC)})
+
+	w := performRequest(http.MethodGet, "/tasks/stats/timeseries?interval=day&tz=UTC&from=2026-03-01T00:00:00Z&to=2026-03-04T00:00:00Z", "", "")
+	expectStatus(t, w, http.StatusOK)
+	var body struct {
+		Series []TimeseriesPoint `json:"series"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	want := []TimeseriesPoint{
+		{Bucket: "2026-03-01", Created: 2},
+		{Bucket: "2026-03-02"},
+		{Bucket: "2026-03-03", Completed: 1},
+	}
+	if len(body.Series) != len(want) {
+		t.Fatalf("series = %+v, want %+v", body.Series, want)
+	}
+	for i := range want {
+		if body.Series[i] != want[i] {
+			t.Errorf("series[%d] = %+v, want %+v", i, body.Series[i], want[i])
+		}
+	}
+
+	w = performRequest(http.MethodGet, "/tasks/stats/timeseries?interval=hour", "", "")
+	expectStatus(t, w, http.StatusBadRequest)
+}
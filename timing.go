@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// --- Время обработки запроса (Server-Timing) ---
+
+// dbTiming - Суммарное время SQL-запросов, выполненных в рамках одного HTTP-запроса
+type dbTiming struct {
+	nanos atomic.Int64
+	count atomic.Int64
+}
+
+// dbTimingKey - Ключ dbTiming в context.Context
+type dbTimingKey struct{}
+
+// dbTimingFrom - Счетчик времени БД из контекста (nil вне HTTP-запроса)
+func dbTimingFrom(ctx context.Context) *dbTiming {
+	if ctx == nil {
+		return nil
+	}
+	timing, _ := ctx.Value(dbTimingKey{}).(*dbTiming)
+	return timing
+}
+
+// Ключ времени начала SQL-запроса в настройках gorm.Statement
+const timingStartKey = "server_timing:start"
+
+// registerTimingCallbacks - Замерять SQL-запросы колбэками GORM вокруг основных операций
+// Учитываются только запросы, выполненные с контекстом HTTP-запроса (WithContext или Session{Context}).
+func registerTimingCallbacks(d *gorm.DB) error {
+	before := func(tx *gorm.DB) {
+		if dbTimingFrom(tx.Statement.Context) != nil {
+			tx.InstanceSet(timingStartKey, time.Now())
+		}
+	}
+	after := func(tx *gorm.DB) {
+		timing := dbTimingFrom(tx.Statement.Context)
+		if timing == nil {
+			return
+		}
+		if start, ok := tx.InstanceGet(timingStartKey); ok {
+			timing.nanos.Add(int64(time.Since(start.(time.Time))))
+			timing.count.Add(1)
+		}
+	}
+
+	callbacks := d.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("timing:before_create", before),
+		callbacks.Create().After("gorm:create").Register("timing:after_create", after),
+		callbacks.Query().Before("gorm:query").Register("timing:before_query", before),
+		callbacks.Query().After("gorm:query").Register("timing:after_query", after),
+		callbacks.Update().Before("gorm:update").Register("timing:before_update", before),
+		callbacks.Update().After("gorm:update").Register("timing:after_update", after),
+		callbacks.Delete().Before("gorm:delete").Register("timing:before_delete", before),
+		callbacks.Delete().After("gorm:delete").Register("timing:after_delete", after),
+		callbacks.Row().Before("gorm:row").Register("timing:before_row", before),
+		callbacks.Row().After("gorm:row").Register("timing:after_row", after),
+		callbacks.Raw().Before("gorm:raw").Register("timing:before_raw", before),
+		callbacks.Raw().After("gorm:raw").Register("timing:after_raw", after),
+	)
+}
+
+// timingWriter - Проставляет заголовки времени непосредственно перед отправкой статуса:
+// после этого заголовки менять уже нельзя
+type timingWriter struct {
+	gin.ResponseWriter
+	start  time.Time
+	timing *dbTiming
+}
+
+func (w *timingWriter) setHeaders() {
+	if w.Written() {
+		return
+	}
+	elapsed := time.Since(w.start)
+	ms := float64(elapsed.Microseconds()) / 1000
+	value := fmt.Sprintf("app;dur=%.1f", ms)
+	if count := w.timing.count.Load(); count > 0 {
+		dbMs := float64(time.Duration(w.timing.nanos.Load()).Microseconds()) / 1000
+		value += fmt.Sprintf(", db;dur=%.1f;desc=\"queries: %d\"", dbMs, count)
+	}
+	w.Header().Set("Server-Timing", value)
+	w.Header().Set("X-Response-Time", fmt.Sprintf("%.1fms", ms))
+}
+
+func (w *timingWriter) WriteHeaderNow() {
+	w.setHeaders()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *timingWriter) Write(data []byte) (int, error) {
+	w.setHeaders()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timingWriter) WriteString(s string) (int, error) {
+	w.setHeaders()
+	return w.ResponseWriter.WriteString(s)
+}
+
+// serverTiming - Заголовки Server-Timing (app - обработка, db - SQL) и X-Response-Time в миллисекундах
+func serverTiming() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timing := &dbTiming{}
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), dbTimingKey{}, timing))
+		c.Writer = &timingWriter{ResponseWriter: c.Writer, start: time.Now(), timing: timing}
+		c.Next()
+	}
+}
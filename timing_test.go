@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+var (
+	executedQueries     atomic.Int64
+	countQueriesOnce    sync.Once
+	serverTimingQueries = regexp.MustCompile(`queries: (\d+)`)
+)
+
+// countAllQueries - Считать все SQL-запросы к тестовой базе, с контекстом запроса или без
+func countAllQueries(t *testing.T) {
+	t.Helper()
+	var err error
+	countQueriesOnce.Do(func() {
+		count := func(*gorm.DB) { executedQueries.Add(1) }
+		callbacks := db.Callback()
+		err = callbacks.Create().After("gorm:create").Register("test:count_create", count)
+		if err == nil {
+			err = callbacks.Query().After("gorm:query").Register("test:count_query", count)
+		}
+		if err == nil {
+			err = callbacks.Update().After("gorm:update").Register("test:count_update", count)
+		}
+		if err == nil {
+			err = callbacks.Delete().After("gorm:delete").Register("test:count_delete", count)
+		}
+		if err == nil {
+			err = callbacks.Row().After("gorm:row").Register("test:count_row", count)
+		}
+		if err == nil {
+			err = callbacks.Raw().After("gorm:raw").Register("test:count_raw", count)
+		}
+	})
+	if err != nil {
+		t.Fatalf("register counting callbacks: %v", err)
+	}
+}
+
+// TestServerTimingCountsAllQueries - Server-Timing учитывает каждый SQL-запрос обработчика,
+// то есть все они выполняются с контекстом HTTP-запроса
+func TestServerTimingCountsAllQueries(t *testing.T) {
+	setupTestDB(t)
+	countAllQueries(t)
+	parent := createTestTask(t, Task{Title: "Проект", Tags: "работа"})
+	createTestTask(t, Task{Title: "Подзадача", Tags: "работа", ParentID: &parent.ID})
+
+	requests := []struct {
+		method, path, body string
+	}{
+		{http.MethodGet, fmt.Sprintf("/tasks/%d?expand=subtasks.count,timeEntries.count,timeEntries.latest", parent.ID), ""},
+		{http.MethodGet, "/tasks/?sort=createdAt", ""},
+		{http.MethodGet, "/tasks/tree", ""},
+		{http.MethodGet, "/tasks/stats", ""},
+		{http.MethodGet, "/tasks/digest", ""},
+		{http.MethodGet, "/tasks/overdue/summary", ""},
+		{http.MethodGet, fmt.Sprintf("/tasks/%d/related", parent.ID), ""},
+		{http.MethodGet, "/tags", ""},
+		{http.MethodPost, fmt.Sprintf("/tasks/%d/toggle", parent.ID), ""},
+		{http.MethodPatch, fmt.Sprintf("/tasks/%d", parent.ID), `{"title": "Проект 2"}`},
+	}
+	for _, r := range requests {
+		executedQueries.Store(0)
+		w := performRequest(r.method, r.path, r.body, "alice")
+		expectStatus(t, w, http.StatusOK)
+		executed := executedQueries.Load()
+
+		reported := int64(0)
+		if m := serverTimingQueries.FindStringSubmatch(w.Header().Get("Server-Timing")); m != nil {
+			reported, _ = strconv.ParseInt(m[1], 10, 64)
+		}
+		if executed == 0 || reported != executed {
+			t.Errorf("%s %s: Server-Timing reports %d queries, %d were executed", r.method, r.path, reported, executed)
+		}
+	}
+}
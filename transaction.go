@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// --- Транзакция на запрос ---
+
+// Открывать транзакцию для изменяющих запросов (REQUEST_TRANSACTIONS)
+var requestTransactions = true
+
+// txContextKey - Ключ транзакции запроса в gin.Context
+const txContextKey = "tx"
+
+// txExemptRoutes - Маршруты, которые сами управляют транзакциями или обращаются к внешним сервисам:
+// держать открытую транзакцию на время импорта пачками или ответа LLM нельзя
+var txExemptRoutes = map[string]bool{
+	readOnlyPath:                      true,
+	"/tasks/query":                    true,
+	"/tasks/import":                   true,
+	"/tasks/stats/batch":              true,
+	"/ai/query":                       true,
+	"/webhooks/deliveries/:id/replay": true,
+}
+
+// pendingInvalidationKey - Ключ pendingInvalidation в context.Context
+type pendingInvalidationKey struct{}
+
+// pendingInvalidation - Задачи, измененные в транзакции запроса: их кэш сбрасывается еще раз после COMMIT
+// Хуки сбрасывают кэш до фиксации, и параллельное чтение успевает вернуть в него строку до изменения.
+type pendingInvalidation struct {
+	mu  sync.Mutex
+	all bool // Изменение по условию: затронута может быть любая задача
+	ids map[uint]bool
+}
+
+// add - Запомнить задачу; id 0 - сбросить после фиксации весь кэш
+func (p *pendingInvalidation) add(id uint) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if id == 0 {
+		p.all = true
+		return
+	}
+	p.ids[id] = true
+}
+
+// flush - Сбросить кэши запомненных задач (вызывается после COMMIT)
+func (p *pendingInvalidation) flush() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.all {
+		invalidateTaskCaches(0)
+		return
+	}
+	if len(p.ids) == 0 {
+		return
+	}
+	taskStatsCache.invalidate()
+	for id := range p.ids {
+		taskCache.remove(id)
+	}
+}
+
+// invalidateTaskCachesOnCommit - Сбросить кэши задач сейчас и, если ctx принадлежит транзакции запроса,
+// еще раз после ее фиксации; без транзакции запроса изменения уже зафиксированы (или фиксирует их вызывающий,
+// который повторяет вызов после своего COMMIT)
+func invalidateTaskCachesOnCommit(ctx context.Context, ids ...uint) {
+	if len(ids) == 0 {
+		ids = []uint{0}
+	}
+	pending, _ := ctx.Value(pendingInvalidationKey{}).(*pendingInvalidation)
+	for _, id := range ids {
+		invalidateTaskCaches(id)
+		if pending != nil {
+			pending.add(id)
+		}
+	}
+}
+
+// txFromContext - Транзакция текущего запроса или глобальное подключение с контекстом запроса, если ее нет
+// (GET-запросы, исключения из txExemptRoutes, REQUEST_TRANSACTIONS=false).
+// Обработчики выполняют все запросы через него: по контексту считаются Server-Timing и requestId в журнале SQL.
+func txFromContext(c *gin.Context) *gorm.DB {
+	if tx, ok := c.Get(txContextKey); ok {
+		return tx.(*gorm.DB)
+	}
+	return db.WithContext(c.Request.Context())
+}
+
+// requestTransaction - Выполнить изменяющий запрос в одной транзакции
+// Ответ буферизуется до фиксации: клиент не получит 200, если COMMIT не прошел.
+// Откат - при панике (она пробрасывается дальше в recovery), ошибках в c.Errors и статусе >= 400.
+// Обработчики работают через txFromContext, и их вложенные Transaction становятся точками сохранения.
+// После фиксации сбрасываются кэши задач, которые запрос изменил (см. invalidateTaskCachesOnCommit).
+func requestTransaction() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if !requestTransactions || txExemptRoutes[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		pending := &pendingInvalidation{ids: make(map[uint]bool)}
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), pendingInvalidationKey{}, pending))
+		tx := db.WithContext(c.Request.Context()).Begin()
+		if tx.Error != nil {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Database is unavailable"})
+			return
+		}
+		c.Set(txContextKey, tx)
+
+		writer := &prettyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		committed := false
+		defer func() {
+			c.Writer = writer.ResponseWriter
+			if !committed {
+				tx.Rollback()
+			}
+		}()
+
+		c.Next()
+
+		if len(c.Errors) == 0 && writer.Status() < http.StatusBadRequest {
+			if err := tx.Commit().Error; err != nil {
+				log.Printf("Failed to commit request transaction: %v", err)
+				c.Writer = writer.ResponseWriter
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save changes"})
+				return
+			}
+			committed = true
+			pending.flush()
+		}
+
+		body := writer.body.Bytes()
+		if len(body) > 0 {
+			writer.ResponseWriter.Write(body)
+		} else {
+			writer.ResponseWriter.WriteHeaderNow()
+		}
+	}
+}
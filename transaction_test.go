@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestInvalidateTaskCachesOnCommit - Строка, которую параллельное чтение вернуло в кэш до COMMIT,
+// сбрасывается после фиксации; кэш остальных задач остается
+func TestInvalidateTaskCachesOnCommit(t *testing.T) {
+	saved := taskCache
+	taskCache = newTaskLRU(10, time.Minute)
+	t.Cleanup(func() { taskCache = saved })
+
+	pending := &pendingInvalidation{ids: make(map[uint]bool)}
+	ctx := context.WithValue(context.Background(), pendingInvalidationKey{}, pending)
+	invalidateTaskCachesOnCommit(ctx, 1)
+
+	_, generation, _ := taskCache.get(1)
+	taskCache.put(Task{ID: 1, Title: "До изменения"}, generation)
+	taskCache.put(Task{ID: 2, Title: "Другая задача"}, generation)
+	pending.flush()
+
+	if _, _, hit := taskCache.get(1); hit {
+		t.Error("task 1 read before the commit is still cached")
+	}
+	if _, _, hit := taskCache.get(2); !hit {
+		t.Error("task 2 was not changed but its cache entry was dropped")
+	}
+}
+
+// TestRequestTransactionKeepsUnrelatedCache - Изменение одной задачи не сбрасывает кэш других
+func TestRequestTransactionKeepsUnrelatedCache(t *testing.T) {
+	setupTestDB(t)
+	taskCache = newTaskLRU(10, time.Minute)
+	changed := createTestTask(t, Task{Title: "Изменяемая"})
+	other := createTestTask(t, Task{Title: "Соседняя"})
+
+	get := func(id uint) string {
+		w := performRequest(http.MethodGet, fmt.Sprintf("/tasks/%d", id), "", "")
+		expectStatus(t, w, http.StatusOK)
+		return w.Header().Get("X-Cache")
+	}
+	get(changed.ID)
+	get(other.ID)
+
+	w := performRequest(http.MethodPatch, fmt.Sprintf("/tasks/%d", changed.ID), `{"title": "Изменена"}`, "")
+	expectStatus(t, w, http.StatusOK)
+
+	if got := get(changed.ID); got != "MISS" {
+		t.Errorf("changed task: X-Cache = %s, want MISS", got)
+	}
+	if got := get(other.ID); got != "HIT" {
+		t.Errorf("unrelated task: X-Cache = %s, want HIT", got)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- Дерево задач ---
+
+// maxTreeDepth - Максимальная глубина дерева (уровней, включая верхний); защищает от
+// бесконечной рекурсии на испорченных данных и от огромных ответов
+const maxTreeDepth = 10
+
+// maxTreeNodes - Максимум задач в одном ответе GET /tasks/tree
+const maxTreeNodes = 5000
+
+// TaskTreeNode - Задача с вложенными подзадачами
+type TaskTreeNode struct {
+	Task
+	Subtasks []*TaskTreeNode `json:"subtasks"`
+}
+
+// buildTaskTree - Собрать дерево из плоского списка, где родители идут раньше подзадач
+// Порядок внутри каждого уровня сохраняется.
+func buildTaskTree(tasks []Task) []*TaskTreeNode {
+	nodes := make(map[uint]*TaskTreeNode, len(tasks))
+	roots := []*TaskTreeNode{}
+	for i := range tasks {
+		node := &TaskTreeNode{Task: tasks[i], Subtasks: []*TaskTreeNode{}}
+		nodes[node.ID] = node
+		if node.ParentID == nil {
+			roots = append(roots, node)
+		} else if parent, ok := nodes[*node.ParentID]; ok {
+			parent.Subtasks = append(parent.Subtasks, node)
+		}
+	}
+	return roots
+}
+
+// GetTaskTree - Задачи верхнего уровня с подзадачами, вложенными рекурсивно (GET /tasks/tree)
+// ?depth= - сколько уровней вернуть (1 - только верхний, по умолчанию и максимум - maxTreeDepth).
+// Фильтры GET /tasks применяются к задачам верхнего уровня, подзадачи возвращаются все.
+// Дерево выбирается одним рекурсивным запросом, без N+1.
+func GetTaskTree(c *gin.Context) {
+	depth := maxTreeDepth
+	if raw := c.Query("depth"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 1 || value > maxTreeDepth {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("depth must be between 1 and %d", maxTreeDepth)})
+			return
+		}
+		depth = value
+	}
+	filter, err := parseTaskFilter(c.Request.URL.Query())
+	if err == nil {
+		err = resolveAssignee(c, &filter)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tx := txFromContext(c)
+	roots := applyTaskFilter(tx.Model(&Task{}).Where("parent_id IS NULL"), filter).Select("id")
+	tasks := []Task{}
+	err = tx.Raw(`WITH RECURSIVE tree AS (
+			SELECT tasks.*, 1 AS depth FROM tasks WHERE id IN (?)
+			UNION ALL
+			SELECT t.*, tree.depth + 1 FROM tasks t JOIN tree ON t.parent_id = tree.id
+			WHERE t.deleted_at IS NULL AND tree.depth < ?
+		)
+		SELECT * FROM tree ORDER BY depth, position, id LIMIT ?`, roots, depth, maxTreeNodes+1).
+		Scan(&tasks).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load task tree"})
+		return
+	}
+	if len(tasks) > maxTreeNodes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("tree has more than %d tasks; narrow it down with filters or a smaller depth", maxTreeNodes)})
+		return
+	}
+
+	enrichTasks(c, tasks)
+	if c.Query("fullDescription") != "true" {
+		for i := range tasks {
+			tasks[i].toListView()
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": buildTaskTree(tasks), "depth": depth, "total": len(tasks)})
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestBuildTaskTree(t *testing.T) {
+	parent := func(id uint) *uint { return &id }
+	tasks := []Task{
+		{ID: 1, Title: "Корень 1"},
+		{ID: 2, Title: "Корень 2"},
+		{ID: 3, Title: "Подзадача 1.1", ParentID: parent(1)},
+		{ID: 4, Title: "Подзадача 1.2", ParentID: parent(1)},
+		{ID: 5, Title: "Подзадача 1.1.1", ParentID: parent(3)},
+	}
+	roots := buildTaskTree(tasks)
+	if len(roots) != 2 || roots[0].ID != 1 || roots[1].ID != 2 {
+		t.Fatalf("roots = %v, want 1 and 2", roots)
+	}
+	children := roots[0].Subtasks
+	if len(children) != 2 || children[0].ID != 3 || children[1].ID != 4 {
+		t.Fatalf("subtasks of 1 = %v, want 3 and 4 in order", children)
+	}
+	if len(children[0].Subtasks) != 1 || children[0].Subtasks[0].ID != 5 || len(roots[1].Subtasks) != 0 {
+		t.Errorf("nested subtasks are not attached to their parents")
+	}
+}
+
+// TestGetTaskTree - ?depth= ограничивает число уровней дерева
+func TestGetTaskTree(t *testing.T) {
+	setupTestDB(t)
+	root := createTestTask(t, Task{Title: "Проект"})
+	child := createTestTask(t, Task{Title: "Этап", ParentID: &root.ID})
+	createTestTask(t, Task{Title: "Шаг", ParentID: &child.ID})
+
+	for _, tt := range []struct {
+		query string
+		total int
+	}{{"", 3}, {"?depth=2", 2}, {"?depth=1", 1}} {
+		w := performRequest(http.MethodGet, "/tasks/tree"+tt.query, "", "")
+		expectStatus(t, w, http.StatusOK)
+		var body struct {
+			Data  []*TaskTreeNode `json:"data"`
+			Total int             `json:"total"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Total != tt.total || len(body.Data) != 1 || body.Data[0].ID != root.ID {
+			t.Errorf("tree%s: total %d, roots %d; want %d tasks under one root", tt.query, body.Total, len(body.Data), tt.total)
+		}
+	}
+	expectStatus(t, performRequest(http.MethodGet, "/tasks/tree?depth=0", "", ""), http.StatusBadRequest)
+}
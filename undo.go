@@ -0,0 +1,102 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// --- Отмена удаления ---
+
+// Сколько действует токен отмены удаления (UNDO_TTL, 0 - отмена выключена)
+var undoTTL = 30 * time.Second
+
+// UndoToken - Одноразовый токен для восстановления мягко удаленной задачи
+// Хранится в БД, а не в памяти: отмена должна работать и на другом экземпляре приложения.
+type UndoToken struct {
+	Token     string    `gorm:"primaryKey"`
+	TaskID    uint      `gorm:"index"`
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time
+}
+
+// issueUndoToken - Выдать токен отмены для только что удаленной задачи (в той же транзакции)
+func issueUndoToken(tx *gorm.DB, taskID uint) (UndoToken, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return UndoToken{}, err
+	}
+	token := UndoToken{Token: hex.EncodeToString(buf), TaskID: taskID, ExpiresAt: time.Now().Add(undoTTL)}
+	return token, tx.Create(&token).Error
+}
+
+// purgeExpiredUndoTokens - Удалить просроченные токены (вызывается очисткой корзины)
+func purgeExpiredUndoTokens() error {
+	return db.Where("expires_at < ?", time.Now()).Delete(&UndoToken{}).Error
+}
+
+// UndoDeleteTask - Восстановить задачу по токену из ответа DELETE /tasks/:id (POST /tasks/undo)
+// Тело: {"undoToken": "..."}. Токен одноразовый; после UNDO_TTL задача остается в корзине
+// и очищается вместе с ней.
+func UndoDeleteTask(c *gin.Context) {
+	var requestBody struct {
+		UndoToken string `json:"undoToken" binding:"required"`
+	}
+	if err := bindJSON(c, &requestBody); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+
+	tx := txFromContext(c)
+	var token UndoToken
+	if err := tx.Where("token = ?", requestBody.UndoToken).First(&token).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Undo token not found or already used", "code": "invalid_undo_token"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to undo deletion"})
+		return
+	}
+	if !time.Now().Before(token.ExpiresAt) {
+		c.JSON(http.StatusGone, gin.H{"error": "Undo window has expired", "code": "undo_expired"})
+		return
+	}
+
+	// Токен удаляется условно: из двух одновременных отмен восстановит задачу только одна
+	result := tx.Where("token = ?", token.Token).Delete(&UndoToken{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to undo deletion"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Undo token not found or already used", "code": "invalid_undo_token"})
+		return
+	}
+	restored := tx.Unscoped().Model(&Task{}).Where("id = ? AND deleted_at IS NOT NULL", token.TaskID).UpdateColumn("deleted_at", nil)
+	if restored.Error != nil {
+		if isDuplicateTitle(restored.Error) {
+			respondDuplicateTitle(c)
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to undo deletion"})
+		return
+	}
+	if restored.RowsAffected == 0 {
+		// Задачу уже восстановили другим способом или окончательно удалили
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task is no longer in the trash"})
+		return
+	}
+	invalidateTaskCachesOnCommit(c.Request.Context(), token.TaskID)
+
+	var task Task
+	if err := tx.First(&task, token.TaskID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load restored task"})
+		return
+	}
+	enrichTask(c, &task)
+	c.JSON(http.StatusOK, task)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// deleteWithUndo - Удалить задачу и вернуть токен отмены из ответа
+func deleteWithUndo(t *testing.T, id uint) string {
+	t.Helper()
+	w := performRequest(http.MethodDelete, fmt.Sprintf("/tasks/%d", id), "", "")
+	expectStatus(t, w, http.StatusOK)
+	var response struct {
+		UndoToken string `json:"undoToken"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || response.UndoToken == "" {
+		t.Fatalf("delete response %s: no undoToken (%v)", w.Body.String(), err)
+	}
+	return response.UndoToken
+}
+
+// TestUndoDelete - Токен из ответа DELETE восстанавливает задачу один раз
+func TestUndoDelete(t *testing.T) {
+	setupTestDB(t)
+	task := createTestTask(t, Task{Title: "Случайно удалена"})
+	token := deleteWithUndo(t, task.ID)
+
+	body := fmt.Sprintf(`{"undoToken": %q}`, token)
+	w := performRequest(http.MethodPost, "/tasks/undo", body, "")
+	expectStatus(t, w, http.StatusOK)
+	var restored Task
+	if err := db.First(&restored, task.ID).Error; err != nil {
+		t.Fatalf("task after undo: %v", err)
+	}
+
+	w = performRequest(http.MethodPost, "/tasks/undo", body, "")
+	expectStatus(t, w, http.StatusNotFound)
+	w = performRequest(http.MethodPost, "/tasks/undo", `{"undoToken": "unknown"}`, "")
+	expectStatus(t, w, http.StatusNotFound)
+}
+
+// TestUndoExpired - После UNDO_TTL токен не действует, а задача остается в корзине
+func TestUndoExpired(t *testing.T) {
+	setupTestDB(t)
+	task := createTestTask(t, Task{Title: "Удалена давно"})
+	token := deleteWithUndo(t, task.ID)
+	if err := db.Model(&UndoToken{}).Where("token = ?", token).Update("expires_at", time.Now().Add(-time.Second)).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	w := performRequest(http.MethodPost, "/tasks/undo", fmt.Sprintf(`{"undoToken": %q}`, token), "")
+	expectStatus(t, w, http.StatusGone)
+	var count int64
+	db.Model(&Task{}).Where("id = ?", task.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("expired undo restored task %d", task.ID)
+	}
+}
+
+// TestForceDeleteHasNoUndo - ?force=true удаляет окончательно и не выдает токен
+func TestForceDeleteHasNoUndo(t *testing.T) {
+	setupTestDB(t)
+	task := createTestTask(t, Task{Title: "Навсегда"})
+	w := performRequest(http.MethodDelete, fmt.Sprintf("/tasks/%d?force=true", task.ID), "", "")
+	expectStatus(t, w, http.StatusNoContent)
+	var count int64
+	db.Model(&UndoToken{}).Count(&count)
+	if count != 0 {
+		t.Errorf("force delete issued %d undo tokens", count)
+	}
+}
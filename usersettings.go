@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm/clause"
+)
+
+// --- Настройки пользователя ---
+
+// UserSettings - Настройки пользователя, известного по заголовку USER_HEADER
+// Отдельной таблицы пользователей нет: строка появляется при первом сохранении настроек.
+type UserSettings struct {
+	UserID     string    `json:"userId" gorm:"primaryKey"`
+	Timezone   string    `json:"timezone"`   // IANA, например Asia/Almaty; пусто - пояс сервера
+	QuietHours string    `json:"quietHours"` // Без напоминаний, например 22:00-07:00; пусто - QUIET_HOURS, off - выключены
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// userLocation - Часовой пояс из настроек текущего пользователя (nil - не задан)
+func userLocation(c *gin.Context) *time.Location {
+	userID := currentUserID(c)
+	if userID == nil {
+		return nil
+	}
+	var settings UserSettings
+	if err := txFromContext(c).Where("user_id = ?", *userID).Limit(1).Find(&settings).Error; err != nil || settings.Timezone == "" {
+		return nil
+	}
+	loc, err := time.LoadLocation(settings.Timezone)
+	if err != nil {
+		log.Printf("Stored timezone %q of user %s is no longer valid: %v", settings.Timezone, *userID, err)
+		return nil
+	}
+	return loc
+}
+
+// requireUser - ID текущего пользователя; без него отвечает 401
+func requireUser(c *gin.Context) (string, bool) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": fmt.Sprintf("%s header is required", userHeader)})
+		return "", false
+	}
+	return *userID, true
+}
+
+// GetMySettings - Настройки текущего пользователя (GET /me/settings)
+func GetMySettings(c *gin.Context) {
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+	settings := UserSettings{UserID: userID}
+	txFromContext(c).Where("user_id = ?", userID).Limit(1).Find(&settings)
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateMySettings - Сохранить настройки текущего пользователя (PUT /me/settings)
+// Тело: {"timezone": "Asia/Almaty", "quietHours": "22:00-07:00"}; пустые строки возвращают
+// пояс сервера и тихие часы по умолчанию, "off" выключает тихие часы совсем.
+func UpdateMySettings(c *gin.Context) {
+	userID, ok := requireUser(c)
+	if !ok {
+		return
+	}
+	var requestBody struct {
+		Timezone   string `json:"timezone"`
+		QuietHours string `json:"quietHours"`
+	}
+	if err := bindJSON(c, &requestBody); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	settings := UserSettings{UserID: userID, Timezone: strings.TrimSpace(requestBody.Timezone)}
+	if settings.Timezone != "" {
+		if _, err := time.LoadLocation(settings.Timezone); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown timezone %q", settings.Timezone)})
+			return
+		}
+	}
+	quiet, err := normalizeQuietHoursSetting(requestBody.QuietHours)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	settings.QuietHours = quiet
+	result := txFromContext(c).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"timezone", "quiet_hours", "updated_at"}),
+	}).Create(&settings)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save settings"})
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
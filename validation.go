@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// --- Ошибки валидации по полям ---
+
+// fieldError - Ошибка проверки, относящаяся к одному полю тела запроса (имя поля - как в JSON)
+type fieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *fieldError) Error() string { return e.Err.Error() }
+func (e *fieldError) Unwrap() error { return e.Err }
+
+// useJSONFieldNames - Называть поля в ошибках валидатора так же, как в JSON (title, а не Title)
+func useJSONFieldNames() {
+	engine, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	engine.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+}
+
+// validationErrors - Разложить ошибку по полям: {"title": "is required"}
+// nil - ошибка не связана с конкретными полями (например, синтаксис JSON).
+func validationErrors(err error) map[string]string {
+	if err == nil {
+		return nil
+	}
+	var fieldErr *fieldError
+	if errors.As(err, &fieldErr) {
+		return map[string]string{fieldErr.Field: fieldErr.Err.Error()}
+	}
+	if field, ok := unknownField(err); ok {
+		return map[string]string{field: "is not a known field"}
+	}
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return nil
+	}
+	fields := make(map[string]string, len(invalid))
+	for _, fe := range invalid {
+		// Namespace начинается с имени структуры: Task.title -> title, .ids[2] для вложенных
+		field := fe.Namespace()
+		if _, rest, ok := strings.Cut(field, "."); ok {
+			field = rest
+		}
+		fields[field] = validationMessage(fe)
+	}
+	return fields
+}
+
+// validationMessage - Человекочитаемое описание нарушенного правила binding
+func validationMessage(fe validator.FieldError) string {
+	kind := fe.Kind()
+	countable := kind == reflect.Slice || kind == reflect.Map || kind == reflect.Array
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		if countable {
+			return fmt.Sprintf("must contain at least %s items", fe.Param())
+		}
+		if kind == reflect.String {
+			return fmt.Sprintf("must be at least %s characters long", fe.Param())
+		}
+		return "must be at least " + fe.Param()
+	case "max":
+		if countable {
+			return fmt.Sprintf("must contain at most %s items", fe.Param())
+		}
+		if kind == reflect.String {
+			return fmt.Sprintf("must be at most %s characters long", fe.Param())
+		}
+		return "must be at most " + fe.Param()
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(fe.Param()), ", ")
+	}
+	return fmt.Sprintf("failed the %q check", fe.Tag())
+}
+
+// respondValidationError - Ответ 400: с картой "errors" по полям, если ошибку удалось разложить
+func respondValidationError(c *gin.Context, err error) {
+	if fields := validationErrors(err); fields != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation failed", "code": "validation_failed", "errors": fields})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// --- Строгий разбор JSON ---
+
+// Отклонять тела с неизвестными полями по умолчанию (STRICT_FIELDS); на запрос - ?strictFields=true|false
+var strictFieldsDefault = false
+
+// strictFields - Включен ли строгий режим для этого запроса
+func strictFields(c *gin.Context) bool {
+	if strict, err := strconv.ParseBool(c.Query("strictFields")); err == nil {
+		return strict
+	}
+	return strictFieldsDefault
+}
+
+// decodeJSON - Разобрать JSON в obj; в строгом режиме опечатка в имени поля ("titel") - ошибка,
+// а не молча пропущенное значение
+func decodeJSON(r io.Reader, obj interface{}, strict bool) error {
+	decoder := json.NewDecoder(r)
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	return decoder.Decode(obj)
+}
+
+// bindJSON - ShouldBindJSON с учетом ?strictFields=
+func bindJSON(c *gin.Context, obj interface{}) error {
+	if !strictFields(c) {
+		return c.ShouldBindJSON(obj)
+	}
+	if c.Request.Body == nil {
+		return errors.New("request body is empty")
+	}
+	if err := decodeJSON(c.Request.Body, obj, true); err != nil {
+		return err
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// unknownField - Имя поля из ошибки DisallowUnknownFields
+// encoding/json не экспортирует тип этой ошибки, поэтому разбирается ее текст.
+func unknownField(err error) (string, bool) {
+	quoted, ok := strings.CutPrefix(err.Error(), "json: unknown field ")
+	if !ok {
+		return "", false
+	}
+	field, err := strconv.Unquote(quoted)
+	return field, err == nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+// TestValidationErrors - Ошибки проверки раскладываются по JSON-именам полей
+func TestValidationErrors(t *testing.T) {
+	useJSONFieldNames()
+	var body struct {
+		Title string `json:"title" binding:"required"`
+		IDs   []uint `json:"ids" binding:"required,min=1"`
+	}
+	body.IDs = []uint{}
+
+	tests := []struct {
+		name string
+		err  error
+		want map[string]string
+	}{
+		{name: "binding", err: binding.Validator.ValidateStruct(&body), want: map[string]string{"title": "is required", "ids": "must contain at least 1 items"}},
+		{name: "field", err: &fieldError{"priority", errors.New("invalid priority")}, want: map[string]string{"priority": "invalid priority"}},
+		{name: "other", err: errors.New("unexpected EOF"), want: nil},
+		{name: "nil", err: nil, want: nil},
+	}
+	for _, tt := range tests {
+		if got := validationErrors(tt.err); !maps.Equal(got, tt.want) {
+			t.Errorf("%s: validationErrors = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestCreateTaskFieldErrors - Ответ 400 на создание задачи содержит карту errors по полям
+func TestCreateTaskFieldErrors(t *testing.T) {
+	setupTestDB(t)
+	for _, tt := range []struct{ body, field string }{
+		{`{"description": "без названия"}`, "title"},
+		{`{"title": "Задача", "priority": "urgent"}`, "priority"},
+		{`{"title": "Задача", "status": "later"}`, "status"},
+	} {
+		w := performRequest(http.MethodPost, "/tasks/", tt.body, "")
+		expectStatus(t, w, http.StatusBadRequest)
+		var body struct {
+			Code   string            `json:"code"`
+			Errors map[string]string `json:"errors"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Code != "validation_failed" || body.Errors[tt.field] == "" {
+			t.Errorf("POST %s = %s, want errors.%s", tt.body, w.Body.String(), tt.field)
+		}
+	}
+}
+
+// TestStrictFields - В строгом режиме неизвестное поле - ошибка с именем поля, без него - пропускается
+func TestStrictFields(t *testing.T) {
+	var task Task
+	if err := decodeJSON(strings.NewReader(`{"title": "Задача", "titel": "опечатка"}`), &task, false); err != nil {
+		t.Errorf("non-strict decode: %v", err)
+	}
+	err := decodeJSON(strings.NewReader(`{"title": "Задача", "titel": "опечатка"}`), &task, true)
+	if got := validationErrors(err); !maps.Equal(got, map[string]string{"titel": "is not a known field"}) {
+		t.Errorf("strict decode: errors = %v (%v), want titel", got, err)
+	}
+}
+
+// TestCreateTaskStrictFields - POST /tasks?strictFields=true отклоняет тело с опечаткой в поле
+func TestCreateTaskStrictFields(t *testing.T) {
+	setupTestDB(t)
+	body := `{"title": "Задача", "priorty": "high"}`
+	w := performRequest(http.MethodPost, "/tasks/?strictFields=true", body, "")
+	expectStatus(t, w, http.StatusBadRequest)
+	if !strings.Contains(w.Body.String(), `"priorty"`) {
+		t.Errorf("strict create = %s, want an error for priorty", w.Body.String())
+	}
+	expectStatus(t, performRequest(http.MethodPost, "/tasks/", body, ""), http.StatusCreated)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// --- Сохраненные представления (saved views) ---
+
+// SavedView - Именованный набор фильтров списка задач, например
+// "Срочное на неделе" = {"priority": "high", "dueBefore": "+7d"}
+type SavedView struct {
+	ID        uint              `json:"id" gorm:"primaryKey"`
+	Name      string            `json:"name" binding:"required"`
+	Filter    map[string]string `json:"filter" gorm:"serializer:json"`
+	CreatedAt time.Time         `json:"createdAt"`
+	UpdatedAt time.Time         `json:"updatedAt"`
+}
+
+// values - Фильтр представления в виде параметров строки запроса
+func (v SavedView) values() url.Values {
+	values := url.Values{}
+	for key, value := range v.Filter {
+		values.Set(key, value)
+	}
+	return values
+}
+
+// validate - Проверить фильтр тем же разбором, что и у GET /tasks
+func (v SavedView) validate() error {
+	for key := range v.Filter {
+		if !taskFilterParams[key] {
+			return fmt.Errorf("unknown filter parameter %q", key)
+		}
+	}
+	_, err := parseTaskFilter(v.values())
+	return err
+}
+
+// parseViewID - Разобрать :id представления
+func parseViewID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid view id", "code": "invalid_id"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// CreateView - Создать сохраненное представление
+func CreateView(c *gin.Context) {
+	var view SavedView
+	if err := bindJSON(c, &view); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if err := view.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	view.ID = 0
+	txFromContext(c).Create(&view)
+	c.Header("Location", resourceLocation("views", view.ID))
+	c.JSON(http.StatusCreated, view)
+}
+
+// GetViews - Получить список сохраненных представлений
+func GetViews(c *gin.Context) {
+	var views []SavedView
+	txFromContext(c).Order("name").Find(&views)
+	c.JSON(http.StatusOK, views)
+}
+
+// GetViewByID - Получить представление по ID
+func GetViewByID(c *gin.Context) {
+	id, ok := parseViewID(c)
+	if !ok {
+		return
+	}
+	var view SavedView
+	if result := txFromContext(c).First(&view, id); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "View not found"})
+		return
+	}
+	c.JSON(http.StatusOK, view)
+}
+
+// UpdateView - Обновить представление
+func UpdateView(c *gin.Context) {
+	id, ok := parseViewID(c)
+	if !ok {
+		return
+	}
+	tx := txFromContext(c)
+	var view SavedView
+	if result := tx.First(&view, id); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "View not found"})
+		return
+	}
+
+	view.Filter = nil // Фильтр заменяется целиком, а не сливается со старым
+	if err := bindJSON(c, &view); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	if err := view.validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	view.ID = id
+	tx.Save(&view)
+	c.JSON(http.StatusOK, view)
+}
+
+// DeleteView - Удалить представление
+func DeleteView(c *gin.Context) {
+	id, ok := parseViewID(c)
+	if !ok {
+		return
+	}
+	if result := txFromContext(c).Delete(&SavedView{}, id); result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "View not found"})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetViewTasks - Выполнить сохраненный фильтр (пагинация и сортировка берутся из текущего запроса)
+func GetViewTasks(c *gin.Context) {
+	id, ok := parseViewID(c)
+	if !ok {
+		return
+	}
+	var view SavedView
+	if result := txFromContext(c).First(&view, id); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "View not found"})
+		return
+	}
+
+	filter, err := parseTaskFilter(view.values())
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Stored filter is no longer valid: " + err.Error()})
+		return
+	}
+	tasks, meta, ok := findTaskPage(c, filter)
+	if !ok {
+		return
+	}
+	writeListResponse(c, tasks, meta)
+}
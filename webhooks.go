@@ -0,0 +1,324 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// --- Вебхуки ---
+
+// События задач, на которые можно подписаться
+const (
+	webhookTaskCreated = "task.created"
+	webhookTaskUpdated = "task.updated"
+	webhookTaskDeleted = "task.deleted"
+)
+
+var webhookEvents = map[string]bool{webhookTaskCreated: true, webhookTaskUpdated: true, webhookTaskDeleted: true}
+
+// Webhook - Подписка внешнего сервиса на события задач
+type Webhook struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	URL       string    `json:"url"`
+	Events    string    `json:"events"` // Через запятую; пусто - все события
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// WebhookDelivery - Попытка доставки события: пишется в той же транзакции, что и изменение задачи,
+// поэтому откат изменения отменяет и доставку. Payload хранится целиком для точного повтора.
+type WebhookDelivery struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	WebhookID     uint       `json:"webhookId" gorm:"index"`
+	Event         string     `json:"event"`
+	Payload       string     `json:"payload"`
+	StatusCode    *int       `json:"statusCode"` // Код ответа последней попытки (nil - ответа не было)
+	Error         string     `json:"error,omitempty"`
+	Attempts      int        `json:"attempts"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	LastAttemptAt *time.Time `json:"lastAttemptAt"`
+	NextAttemptAt *time.Time `json:"nextAttemptAt"` // nil - больше не отправляется автоматически
+	DeliveredAt   *time.Time `json:"deliveredAt"`
+
+	Status string `json:"status" gorm:"-"` // pending, delivered или failed, вычисляется
+}
+
+// Сколько раз доставка повторяется автоматически (WEBHOOK_MAX_ATTEMPTS); дальше - только replay
+var webhookMaxAttempts = 5
+
+// webhookHTTPClient - Клиент для доставки: медленный получатель не должен задерживать очередь
+var webhookHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// webhookBackoff - Пауза перед повтором после attempts неудачных попыток: 30s, 1m, 2m, 4m...
+func webhookBackoff(attempts int) time.Duration {
+	return 30 * time.Second << min(attempts-1, 10)
+}
+
+// computeStatus - Заполнить вычисляемое поле Status
+func (d *WebhookDelivery) computeStatus() {
+	switch {
+	case d.DeliveredAt != nil:
+		d.Status = "delivered"
+	case d.NextAttemptAt == nil:
+		d.Status = "failed"
+	default:
+		d.Status = "pending"
+	}
+}
+
+// enqueueWebhookEvent - Поставить событие в очередь для всех подписанных вебхуков
+// Вызывается из хуков задачи; массовые изменения по условию (без id) событий не создают.
+func enqueueWebhookEvent(tx *gorm.DB, event string, task *Task) error {
+	if task.ID == 0 {
+		return nil
+	}
+	var hooks []Webhook
+	if err := tx.Where("events = '' OR ? = ANY(string_to_array(events, ','))", event).Find(&hooks).Error; err != nil {
+		return err
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+	now := time.Now()
+	payload, err := json.Marshal(gin.H{"event": event, "occurredAt": now, "task": task})
+	if err != nil {
+		return err
+	}
+	deliveries := make([]WebhookDelivery, len(hooks))
+	for i, hook := range hooks {
+		deliveries[i] = WebhookDelivery{WebhookID: hook.ID, Event: event, Payload: string(payload), NextAttemptAt: &now}
+	}
+	return tx.Create(&deliveries).Error
+}
+
+// deliverWebhook - Отправить доставку на url и записать результат попытки
+func deliverWebhook(delivery *WebhookDelivery, url string) error {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.StatusCode = nil
+	delivery.Error = ""
+
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(delivery.Payload))
+	if err == nil {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Webhook-Event", delivery.Event)
+		req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+		var resp *http.Response
+		if resp, err = webhookHTTPClient.Do(req); err == nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+			delivery.StatusCode = &resp.StatusCode
+			if resp.StatusCode < 200 || resp.StatusCode > 299 {
+				err = fmt.Errorf("unexpected status %d", resp.StatusCode)
+			}
+		}
+	}
+
+	switch {
+	case err == nil:
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+	case delivery.Attempts >= webhookMaxAttempts:
+		delivery.Error = err.Error()
+		delivery.NextAttemptAt = nil
+	default:
+		delivery.Error = err.Error()
+		next := now.Add(webhookBackoff(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+	}
+	return db.Model(delivery).
+		Select("status_code", "error", "attempts", "last_attempt_at", "next_attempt_at", "delivered_at").
+		Updates(delivery).Error
+}
+
+// dispatchWebhooks - Отправить доставки, время которых наступило
+func dispatchWebhooks(now time.Time) (int, error) {
+	var due []struct {
+		WebhookDelivery
+		URL string
+	}
+	err := db.Table("webhook_deliveries").
+		Select("webhook_deliveries.*, webhooks.url").
+		Joins("JOIN webhooks ON webhooks.id = webhook_deliveries.webhook_id").
+		Where("webhook_deliveries.next_attempt_at <= ?", now).
+		Order("webhook_deliveries.id").
+		Limit(100).
+		Scan(&due).Error
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	for i := range due {
+		if err := deliverWebhook(&due[i].WebhookDelivery, due[i].URL); err != nil {
+			return delivered, err
+		}
+		if due[i].DeliveredAt != nil {
+			delivered++
+		} else {
+			log.Printf("Webhook delivery %d (%s) failed, attempt %d: %s", due[i].ID, due[i].Event, due[i].Attempts, due[i].Error)
+		}
+	}
+	return delivered, nil
+}
+
+// runWebhookDispatcher - Периодически отправлять очередь вебхуков (интервал WEBHOOK_INTERVAL)
+func runWebhookDispatcher() {
+	ticker := time.NewTicker(getEnvDuration("WEBHOOK_INTERVAL", 5*time.Second))
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		if readOnly.Load() {
+			continue
+		}
+		if _, err := dispatchWebhooks(now); err != nil {
+			log.Printf("Webhook dispatcher failed: %v", err)
+		}
+	}
+}
+
+// CreateWebhook - Подписаться на события задач (POST /webhooks)
+// Тело: {"url": "https://...", "events": ["task.created"]}; без events - все события.
+func CreateWebhook(c *gin.Context) {
+	var requestBody struct {
+		URL    string   `json:"url" binding:"required"`
+		Events []string `json:"events"`
+	}
+	if err := bindJSON(c, &requestBody); err != nil {
+		respondValidationError(c, err)
+		return
+	}
+	url, err := validateLinkURL(requestBody.URL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "code": "invalid_url"})
+		return
+	}
+	for _, event := range requestBody.Events {
+		if !webhookEvents[event] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown event %q, expected task.created, task.updated or task.deleted", event)})
+			return
+		}
+	}
+	hook := Webhook{URL: url, Events: strings.Join(requestBody.Events, ",")}
+	if result := txFromContext(c).Create(&hook); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+	c.Header("Location", resourceLocation("webhooks", hook.ID))
+	c.JSON(http.StatusCreated, hook)
+}
+
+// GetWebhooks - Список вебхуков (GET /webhooks)
+func GetWebhooks(c *gin.Context) {
+	hooks := []Webhook{}
+	if result := txFromContext(c).Order("id").Find(&hooks); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load webhooks"})
+		return
+	}
+	c.JSON(http.StatusOK, hooks)
+}
+
+// DeleteWebhook - Удалить вебхук вместе с журналом доставок (DELETE /webhooks/:id)
+func DeleteWebhook(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	result := txFromContext(c).Delete(&Webhook{}, id)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetWebhookDeliveries - Журнал доставок вебхука, новые первыми (GET /webhooks/:id/deliveries)
+// ?status=pending|delivered|failed, ?limit= (по умолчанию 50, максимум 200).
+func GetWebhookDeliveries(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var hook Webhook
+	if result := txFromContext(c).First(&hook, id); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+	limit := 50
+	if raw := c.Query("limit"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < 1 || value > 200 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+			return
+		}
+		limit = value
+	}
+
+	query := txFromContext(c).Where("webhook_id = ?", id)
+	switch status := c.Query("status"); status {
+	case "":
+	case "pending":
+		query = query.Where("delivered_at IS NULL AND next_attempt_at IS NOT NULL")
+	case "delivered":
+		query = query.Where("delivered_at IS NOT NULL")
+	case "failed":
+		query = query.Where("delivered_at IS NULL AND next_attempt_at IS NULL")
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, delivered or failed"})
+		return
+	}
+	deliveries := []WebhookDelivery{}
+	if result := query.Order("id DESC").Limit(limit).Find(&deliveries); result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load deliveries"})
+		return
+	}
+	for i := range deliveries {
+		deliveries[i].computeStatus()
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// ReplayWebhookDelivery - Повторно отправить доставку с тем же payload (POST /webhooks/deliveries/:id/replay)
+// Отправка синхронная, в ответе - результат попытки. Лимит автоматических попыток не действует.
+func ReplayWebhookDelivery(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	tx := txFromContext(c)
+	var delivery WebhookDelivery
+	if result := tx.First(&delivery, id); result.Error != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+		return
+	}
+	var hook Webhook
+	if err := tx.First(&hook, delivery.WebhookID).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	} else if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load webhook"})
+		return
+	}
+
+	// Доставленная повторно отправляется как новая попытка: журнал хранит последнюю
+	delivery.DeliveredAt = nil
+	if err := deliverWebhook(&delivery, hook.URL); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record delivery attempt"})
+		return
+	}
+	delivery.computeStatus()
+	c.JSON(http.StatusOK, delivery)
+}
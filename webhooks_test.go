@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// cleanWebhooks - Удалить вебхуки и журнал доставок до и после теста
+func cleanWebhooks(t *testing.T) {
+	t.Helper()
+	clean := func() { db.Exec("TRUNCATE webhooks, webhook_deliveries RESTART IDENTITY CASCADE") }
+	clean()
+	t.Cleanup(clean)
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{4, 4 * time.Minute},
+		{50, 30 * time.Second << 10},
+	}
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempts); got != tt.want {
+			t.Errorf("webhookBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+// TestWebhookDeliveryRetryAndReplay - Неудачная доставка остается в очереди со следующей попыткой,
+// а replay отправляет тот же payload и отмечает доставку
+func TestWebhookDeliveryRetryAndReplay(t *testing.T) {
+	setupTestDB(t)
+	cleanWebhooks(t)
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer receiver.Close()
+	if err := db.Create(&Webhook{URL: receiver.URL, Events: webhookTaskCreated}).Error; err != nil {
+		t.Fatal(err)
+	}
+	createTestTask(t, Task{Title: "Событие"})
+
+	if _, err := dispatchWebhooks(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	var delivery WebhookDelivery
+	if err := db.First(&delivery).Error; err != nil {
+		t.Fatal(err)
+	}
+	delivery.computeStatus()
+	if delivery.Status != "pending" || delivery.Attempts != 1 || delivery.StatusCode == nil || *delivery.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("after a failed attempt: %+v, want pending with status code 503", delivery)
+	}
+
+	t.Setenv("ADMIN_TOKEN", "secret")
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/webhooks/deliveries/%d/replay", delivery.ID), nil)
+	req.Header.Set("X-Admin-Token", "secret")
+	w := httptest.NewRecorder()
+	testRouter.ServeHTTP(w, req)
+	expectStatus(t, w, http.StatusOK)
+	if err := json.Unmarshal(w.Body.Bytes(), &delivery); err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Status != "delivered" || delivery.Attempts != 2 || calls.Load() != 2 {
+		t.Errorf("after replay: status %s, attempts %d, calls %d; want delivered after 2 attempts", delivery.Status, delivery.Attempts, calls.Load())
+	}
+}